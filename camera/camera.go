@@ -0,0 +1,190 @@
+// camera follows a target around the world and builds the view matrix of the canvas
+package camera
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Target is followed by the camera
+type Target interface {
+	Center() pixel.Vec
+}
+
+// Mover targets give their velocity, used for the lookahead
+type Mover interface {
+	Velocity() pixel.Vec
+}
+
+// Grounder targets tell if they stand on something, used for the platform snapping
+type Grounder interface {
+	OnGround() bool
+}
+
+type Camera struct {
+	Pos  pixel.Vec // center of the view in world space
+	Size pixel.Vec // size of the view at zoom 1
+
+	// the target can move inside the deadzone (relative to Pos) without moving the camera
+	Deadzone pixel.Rect
+	// the view never leaves the bounds, ignored if empty
+	Bounds pixel.Rect
+	// how many seconds of the target velocity the camera looks ahead
+	Lookahead float64
+	// part of the distance to the target still left after one second
+	Smoothing float64
+	// vertically follow the target only when it lands, or falls below its last ground
+	PlatformSnap bool
+
+	zoom, zoomTo, zoomSmoothing float64
+
+	target  Target
+	ahead   pixel.Vec
+	groundY float64
+
+	// transition from a previous focus point to the new target
+	from           pixel.Vec
+	transition     float64
+	transitionTime float64
+}
+
+func (c *Camera) Target() Target {
+	return c.target
+}
+
+// SetTarget follows t, blending from the current position during the given seconds
+func (c *Camera) SetTarget(t Target, transition float64) {
+	c.from = c.Pos
+	c.target = t
+	c.transition = 0
+	c.transitionTime = transition
+	c.ahead = pixel.ZV
+	if t != nil {
+		c.groundY = t.Center().Y
+	}
+}
+
+// Snap centers the camera on its target without smoothing
+func (c *Camera) Snap() {
+	c.transitionTime = 0
+	c.zoom = c.zoomTo
+	c.ahead = pixel.ZV
+	if c.target != nil {
+		c.Pos = c.target.Center()
+		c.groundY = c.Pos.Y
+	}
+	c.Pos = c.clamp(c.Pos)
+}
+
+func (c *Camera) Zoom() float64 {
+	return c.zoom
+}
+
+// ZoomTo changes the zoom, the smoothing works like Smoothing, 0 zooms instantly
+func (c *Camera) ZoomTo(zoom, smoothing float64) {
+	c.zoomTo = zoom
+	c.zoomSmoothing = smoothing
+	if smoothing <= 0 {
+		c.zoom = zoom
+	}
+}
+
+// focus returns the point the camera should be centered on
+func (c *Camera) focus(dt float64) pixel.Vec {
+	if c.target == nil {
+		return c.Pos
+	}
+	pos := c.target.Center()
+
+	// look ahead in the direction the target is moving
+	if m, ok := c.target.(Mover); ok && c.Lookahead > 0 {
+		c.ahead = pixel.Lerp(c.ahead, m.Velocity().Scaled(c.Lookahead), c.lerp(dt))
+		pos = pos.Add(c.ahead)
+	}
+
+	// only follow vertically on landings, or when falling below the last ground
+	if g, ok := c.target.(Grounder); ok && c.PlatformSnap {
+		if g.OnGround() || pos.Y < c.groundY {
+			c.groundY = pos.Y
+		}
+		pos.Y = c.groundY
+	}
+
+	// keep the camera still while the focus is inside the deadzone
+	goal := c.Pos
+	switch {
+	case pos.X < c.Pos.X+c.Deadzone.Min.X:
+		goal.X = pos.X - c.Deadzone.Min.X
+	case pos.X > c.Pos.X+c.Deadzone.Max.X:
+		goal.X = pos.X - c.Deadzone.Max.X
+	}
+	switch {
+	case pos.Y < c.Pos.Y+c.Deadzone.Min.Y:
+		goal.Y = pos.Y - c.Deadzone.Min.Y
+	case pos.Y > c.Pos.Y+c.Deadzone.Max.Y:
+		goal.Y = pos.Y - c.Deadzone.Max.Y
+	}
+	return goal
+}
+
+// lerp returns the smoothing factor for a frame of dt seconds
+func (c *Camera) lerp(dt float64) float64 {
+	return 1 - math.Pow(c.Smoothing, dt)
+}
+
+// clamp keeps the view inside the bounds
+func (c *Camera) clamp(pos pixel.Vec) pixel.Vec {
+	if c.Bounds.Area() == 0 {
+		return pos
+	}
+	half := c.Size.Scaled(0.5 / c.zoom)
+	clampAxis := func(v, min, max, half float64) float64 {
+		if max-min < 2*half {
+			return (min + max) / 2
+		}
+		return math.Max(min+half, math.Min(max-half, v))
+	}
+	pos.X = clampAxis(pos.X, c.Bounds.Min.X, c.Bounds.Max.X, half.X)
+	pos.Y = clampAxis(pos.Y, c.Bounds.Min.Y, c.Bounds.Max.Y, half.Y)
+	return pos
+}
+
+func (c *Camera) Update(dt float64) {
+	c.zoom += (c.zoomTo - c.zoom) * (1 - math.Pow(c.zoomSmoothing, dt))
+
+	goal := c.focus(dt)
+	if c.transition < c.transitionTime {
+		// ease from the previous position to the new target
+		c.transition += dt
+		t := math.Min(c.transition/c.transitionTime, 1)
+		t = t * t * (3 - 2*t)
+		c.Pos = c.clamp(pixel.Lerp(c.from, goal, t))
+		return
+	}
+	c.Pos = c.clamp(pixel.Lerp(c.Pos, goal, c.lerp(dt)))
+}
+
+// Matrix is the matrix to give to canvas.SetMatrix
+func (c *Camera) Matrix() pixel.Matrix {
+	return pixel.IM.Moved(c.Pos.Scaled(-1)).Scaled(pixel.ZV, c.zoom)
+}
+
+// View is the world rectangle seen by the camera
+func (c *Camera) View() pixel.Rect {
+	half := c.Size.Scaled(0.5 / c.zoom)
+	return pixel.Rect{Min: c.Pos.Sub(half), Max: c.Pos.Add(half)}
+}
+
+// New creates a camera of the given view size following the target
+func New(size pixel.Vec, target Target) *Camera {
+	c := &Camera{
+		Size:      size,
+		Smoothing: 1.0 / 128,
+		zoom:      1,
+		zoomTo:    1,
+	}
+	c.SetTarget(target, 0)
+	c.Snap()
+	return c
+}
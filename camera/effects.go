@@ -0,0 +1,138 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// EffectsEnabled turns every shake and zoom punch off when false, for accessibility
+var EffectsEnabled = true
+
+// Curve maps the remaining part of an effect (1 to 0) to its strength (1 to 0)
+type Curve func(t float64) float64
+
+func Linear(t float64) float64    { return t }
+func Quadratic(t float64) float64 { return t * t }
+func Cubic(t float64) float64     { return t * t * t }
+
+// punch is a short zoom in (or out) decaying over its duration
+type punch struct {
+	zoom     float64
+	duration float64
+	left     float64
+	curve    Curve
+}
+
+// Effects shakes and punches the view matrix of a camera.
+// The shake is trauma based: the trauma decays linearly and the shake strength is Curve(trauma).
+type Effects struct {
+	MaxOffset float64 // offset of the view at full trauma, in canvas pixels
+	MaxAngle  float64 // rotation of the view at full trauma, in radians
+	Frequency float64 // speed of the shake noise
+	Decay     float64 // trauma lost per second
+	Curve     Curve
+
+	trauma  float64
+	time    float64
+	punches []punch
+}
+
+// AddTrauma shakes the camera, the trauma is kept between 0 and 1
+func (e *Effects) AddTrauma(amount float64) {
+	if !EffectsEnabled {
+		return
+	}
+	e.trauma = math.Max(0, math.Min(1, e.trauma+amount))
+}
+
+func (e *Effects) Trauma() float64 {
+	return e.trauma
+}
+
+// Punch zooms by the given amount (0.1 is 10% closer) and back during duration seconds
+func (e *Effects) Punch(zoom, duration float64, curve Curve) {
+	if !EffectsEnabled || duration <= 0 {
+		return
+	}
+	if curve == nil {
+		curve = Quadratic
+	}
+	e.punches = append(e.punches, punch{
+		zoom:     zoom,
+		duration: duration,
+		left:     duration,
+		curve:    curve,
+	})
+}
+
+// Reset stops every effect
+func (e *Effects) Reset() {
+	e.trauma = 0
+	e.punches = e.punches[:0]
+}
+
+func (e *Effects) Update(dt float64) {
+	e.time += dt
+	e.trauma = math.Max(0, e.trauma-e.Decay*dt)
+
+	punches := e.punches[:0]
+	for _, p := range e.punches {
+		p.left -= dt
+		if p.left > 0 {
+			punches = append(punches, p)
+		}
+	}
+	e.punches = punches
+}
+
+// noise is a cheap smooth noise between -1 and 1, a different one for each seed
+func noise(t, seed float64) float64 {
+	return (math.Sin(t*1.0+seed*12.9898) + math.Sin(t*2.3+seed*78.233) + math.Sin(t*4.7+seed*37.719)) / 3
+}
+
+// Apply adds the shake and the zoom punches to the view matrix m
+func (e *Effects) Apply(m pixel.Matrix) pixel.Matrix {
+	if !EffectsEnabled {
+		return m
+	}
+
+	zoom := 1.0
+	for _, p := range e.punches {
+		zoom += p.zoom * p.curve(p.left/p.duration)
+	}
+
+	shake := e.Curve(e.trauma)
+	t := e.time * e.Frequency
+	offset := pixel.V(noise(t, 1), noise(t, 2)).Scaled(e.MaxOffset * shake)
+	angle := noise(t, 3) * e.MaxAngle * shake
+
+	// m moves the world into view space, centered on the camera, so the effects are applied around the origin
+	return m.Moved(offset).Rotated(pixel.ZV, angle).Scaled(pixel.ZV, zoom)
+}
+
+// View grows r, the world rectangle seen through the view matrix m, to all that is seen once Apply shakes m
+func (e *Effects) View(m pixel.Matrix, r pixel.Rect) pixel.Rect {
+	shaken := e.Apply(m)
+	var view pixel.Rect
+	for i, v := range r.Vertices() {
+		// the corner of the canvas showing v without the effects shows p with them
+		p := shaken.Unproject(m.Project(v))
+		if i == 0 {
+			view = pixel.Rect{Min: p, Max: p}
+			continue
+		}
+		view = view.Union(pixel.Rect{Min: p, Max: p})
+	}
+	return view
+}
+
+func NewEffects() *Effects {
+	return &Effects{
+		MaxOffset: 6,
+		MaxAngle:  0.05,
+		Frequency: 30,
+		Decay:     1.5,
+		Curve:     Quadratic,
+	}
+}
@@ -0,0 +1,28 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestEffectsView(t *testing.T) {
+	cam := New(pixel.V(320, 240), nil)
+	cam.ZoomTo(2, 0)
+	fx := NewEffects()
+	fx.AddTrauma(1)
+	fx.Punch(-0.2, 1, Linear)
+
+	canvas := pixel.R(-160, -120, 160, 120)
+	for i := 0; i < 60; i++ {
+		fx.Update(1.0 / 60)
+		shaken := fx.Apply(cam.Matrix())
+		view := fx.View(cam.Matrix(), cam.View())
+		for _, c := range canvas.Vertices() {
+			// a little slack for the rounding of the corners exactly on the edge
+			if p := shaken.Unproject(c); !view.Resized(view.Center(), view.Size().Add(pixel.V(1e-6, 1e-6))).Contains(p) {
+				t.Fatalf("step %d: the canvas shows %v, out of the view %v", i, p, view)
+			}
+		}
+	}
+}
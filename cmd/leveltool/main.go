@@ -0,0 +1,148 @@
+// leveltool checks level files without opening a window
+//
+//	leveltool reach [-movement file] <level>                 reports the platforms the gopher can not reach and the path to the goal
+//	leveltool solve [-movement file] [-budget n] <level>     plays the level with a bot and prints the inputs reaching the goal
+//
+// Both exit with 1 when the goal can not be reached, so they can check levels after physics changes.
+// The gopher moves with the movement file when given, else with the default movement.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/unknownTravelers/3D-jump-infinite/loader"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: leveltool reach [-movement file] <level>")
+	fmt.Fprintln(os.Stderr, "       leveltool solve [-movement file] [-budget n] <level>")
+	os.Exit(2)
+}
+
+// loadLevel loads a level file or a Tiled map into the scene
+func loadLevel(path string, scene loader.Scene) *loader.LevelInfo {
+	var lvl *loader.LevelInfo
+	var err error
+	switch filepath.Ext(path) {
+	case ".tmx", ".tmj":
+		lvl, err = loader.Tiled(path, scene)
+	default:
+		lvl, err = loader.Level(path, scene)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return lvl
+}
+
+// gopherOptions makes the gopher move as described by the movement file, if any
+func gopherOptions(movePath string) []objects.GopherOption {
+	if movePath == "" {
+		return nil
+	}
+	move, err := loader.Movement(movePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return []objects.GopherOption{objects.WithMovement(move)}
+}
+
+func reach(path, movePath string) bool {
+	scene := objects.NewScene()
+	lvl := loadLevel(path, scene)
+
+	// only the physics of the gopher are used, it needs no sprites
+	goph, err := objects.NewGopher(nil, nil, gopherOptions(movePath)...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	r := objects.AnalyzeReachability(scene, goph, lvl.Spawn)
+
+	describe := func(i int) string {
+		s := r.Surfaces[i]
+		return fmt.Sprintf("#%d %T (id %d) %v", i, s.Owner, scene.ID(s.Owner), s.Rect)
+	}
+
+	fmt.Printf("level %q: %d surfaces\n", lvl.Name, len(r.Surfaces))
+	if r.Start < 0 {
+		fmt.Println("the gopher falls forever from the spawn", lvl.Spawn)
+		return false
+	}
+
+	if unreachable := r.Unreachable(); len(unreachable) > 0 {
+		fmt.Printf("%d unreachable:\n", len(unreachable))
+		for _, i := range unreachable {
+			fmt.Println("  " + describe(i))
+		}
+	}
+
+	if !r.GoalReachable {
+		fmt.Println("the goal can not be reached")
+		return false
+	}
+	fmt.Println("critical path to the goal:")
+	for _, i := range r.CriticalPath {
+		fmt.Println("  " + describe(i))
+	}
+	return true
+}
+
+func solve(path, movePath string, budget int) bool {
+	scene := objects.NewScene()
+	lvl := loadLevel(path, scene)
+
+	goph, err := objects.NewGopher(nil, nil, gopherOptions(movePath)...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	goph.Teleport(lvl.Spawn)
+	inputs, err := objects.SolveLevel(scene, goph, budget)
+	if err != nil {
+		fmt.Printf("level %q: %v\n", lvl.Name, err)
+		return false
+	}
+	frames := 0
+	for _, in := range inputs {
+		frames += in.Frames
+	}
+	fmt.Printf("level %q: solved in %.2fs\n", lvl.Name, float64(frames)*objects.BotStep)
+	fmt.Println(objects.BotInputs(inputs))
+	return true
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		usage()
+	}
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	movePath := fs.String("movement", "", "movement file of the gopher")
+	ok := true
+	switch os.Args[1] {
+	case "reach":
+		fs.Parse(os.Args[2:])
+		if fs.NArg() != 1 {
+			usage()
+		}
+		ok = reach(fs.Arg(0), *movePath)
+	case "solve":
+		budget := fs.Int("budget", 200000, "states the bot searches before giving up")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() != 1 {
+			usage()
+		}
+		ok = solve(fs.Arg(0), *movePath, *budget)
+	default:
+		usage()
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -1,21 +1,18 @@
+// controls holds the player input of the current frame, set by the keyboard or by a bot
 package controls
 
 import (
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
 )
 
+// Controls.X is the horizontal direction, Controls.Y is 1 on the frame jump is pressed
 var Controls = pixel.ZV
 
-func Update(win *pixelgl.Window) {
-	Controls = pixel.ZV
-	if win.Pressed(pixelgl.KeyLeft) {
-		Controls.X--
-	}
-	if win.Pressed(pixelgl.KeyRight) {
-		Controls.X++
-	}
-	if win.JustPressed(pixelgl.KeyUp) {
-		Controls.Y = 1
-	}
-}
+// JumpHeld is whether jump is held down, releasing it early makes a shorter jump
+var JumpHeld bool
+
+// Dash is true on the frame dash is pressed, Down while down is held
+var Dash, Down bool
+
+// Fire is true while fire is held
+var Fire bool
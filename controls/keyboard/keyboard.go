@@ -0,0 +1,25 @@
+// keyboard sets the controls from the keys pressed in a window
+package keyboard
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/unknownTravelers/3D-jump-infinite/controls"
+)
+
+func Update(win *pixelgl.Window) {
+	controls.Controls = pixel.ZV
+	if win.Pressed(pixelgl.KeyLeft) {
+		controls.Controls.X--
+	}
+	if win.Pressed(pixelgl.KeyRight) {
+		controls.Controls.X++
+	}
+	if win.JustPressed(pixelgl.KeyUp) {
+		controls.Controls.Y = 1
+	}
+	controls.JumpHeld = win.Pressed(pixelgl.KeyUp)
+	controls.Dash = win.JustPressed(pixelgl.KeyLeftShift)
+	controls.Down = win.Pressed(pixelgl.KeyDown)
+	controls.Fire = win.Pressed(pixelgl.KeyX)
+}
@@ -4,6 +4,7 @@ go 1.21.6
 
 require (
 	github.com/faiface/pixel v0.10.0
+	github.com/pkg/errors v0.8.1
 	golang.org/x/image v0.0.0-20200801110659-972c09e46d76
 )
 
@@ -13,5 +14,4 @@ require (
 	github.com/go-gl/gl v0.0.0-20190320180904-bf2b1f2f34d7 // indirect
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20200707082815-5321531c36a2 // indirect
 	github.com/go-gl/mathgl v0.0.0-20190416160123-c4601bc793c7 // indirect
-	github.com/pkg/errors v0.8.1 // indirect
 )
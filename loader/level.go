@@ -0,0 +1,376 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+// LevelVersion is the version of the level format written by this loader
+const LevelVersion = 1
+
+// Scene is what the level loader fills, objects.NewScene() implements it
+type Scene interface {
+	AddObjects(o ...objects.Object)
+	Tag(o objects.Object, tags ...string)
+	SetLayer(o objects.Object, l objects.Layer, z int)
+}
+
+// LevelInfo is everything in a level file besides its objects
+type LevelInfo struct {
+	Name         string
+	Spawn        pixel.Vec
+	Background   pixel.RGBA
+	CameraBounds pixel.Rect
+}
+
+// levelFile is the JSON layout of a level:
+//
+//	{
+//		"version": 1,
+//		"name": "zig-zag",
+//		"spawn": [0, 0],
+//		"background": "#000000",
+//		"camera": {"bounds": [-260, -220, 220, 120]},
+//		"objects": [
+//			{"type": "platform", "rect": [-50, -34, 50, -32], "color": "#ff8000", "material": "ice"},
+//			{"type": "wall", "rect": [60, -34, 70, 40]},
+//			{"type": "moving", "rect": [0, 0, 20, 2], "path": [[80, 0], [80, 60]], "properties": {"speed": 32, "mode": "pingpong"}},
+//			{"type": "crumbling", "rect": [90, 20, 110, 22], "properties": {"delay": 0.5, "respawn": 3}},
+//			{"type": "blinking", "rect": [120, 30, 140, 32], "properties": {"on": 2, "off": 1}},
+//			{"type": "toggle", "rect": [150, 40, 170, 42], "tags": ["door"], "properties": {"on": false}},
+//			{"type": "switch", "pos": [100, 22], "properties": {"target": "door"}},
+//			{"type": "platform", "rect": [180, 0, 220, 2], "properties": {"bounce": 0.8, "conveyor": -30}},
+//			{"type": "spring", "pos": [230, 0], "properties": {"strength": 300, "angle": 60}},
+//			{"type": "spikes", "rect": [240, 0, 260, 4]},
+//			{"type": "checkpoint", "pos": [270, 0]},
+//			{"type": "killplane", "pos": [0, -300]},
+//			{"type": "coin", "pos": [20, 10]},
+//			{"type": "gem", "pos": [40, 30], "properties": {"value": 25}},
+//			{"type": "walker", "pos": [-30, -32], "properties": {"dir": -1}},
+//			{"type": "chaser", "pos": [0, -150], "properties": {"range": 120}},
+//			{"type": "chaser", "pos": [60, -150], "properties": {"behaviour": "pursue"}},
+//			{"type": "shooter", "pos": [-80, -150], "properties": {"interval": 2}},
+//			{"type": "goal", "pos": [-75, 40], "properties": {"radius": 18}}
+//		]
+//	}
+type levelFile struct {
+	Version    int      `json:"version"`
+	Name       string   `json:"name"`
+	Spawn      *jsonVec `json:"spawn"`
+	Background string   `json:"background"`
+	Camera     struct {
+		Bounds *jsonRect `json:"bounds"`
+	} `json:"camera"`
+	Objects []json.RawMessage `json:"objects"`
+}
+
+type levelObject struct {
+	Type       string             `json:"type"`
+	Rect       *jsonRect          `json:"rect"`
+	Pos        *jsonVec           `json:"pos"`
+	Path       []jsonVec          `json:"path"`
+	Color      string             `json:"color"`
+	Material   string             `json:"material"`
+	Tags       []string           `json:"tags"`
+	Layer      string             `json:"layer"`
+	Z          int                `json:"z"`
+	Properties objects.Properties `json:"properties"`
+}
+
+type jsonVec pixel.Vec
+
+func (v *jsonVec) UnmarshalJSON(data []byte) error {
+	var xy []float64
+	if err := json.Unmarshal(data, &xy); err != nil || len(xy) != 2 {
+		return fmt.Errorf("expected a position [x, y], got %s", data)
+	}
+	*v = jsonVec(pixel.V(xy[0], xy[1]))
+	return nil
+}
+
+type jsonRect pixel.Rect
+
+func (r *jsonRect) UnmarshalJSON(data []byte) error {
+	var c []float64
+	if err := json.Unmarshal(data, &c); err != nil || len(c) != 4 {
+		return fmt.Errorf("expected a rectangle [minX, minY, maxX, maxY], got %s", data)
+	}
+	*r = jsonRect(pixel.R(c[0], c[1], c[2], c[3]).Norm())
+	return nil
+}
+
+// parseColor parses "#rrggbb" and "#rrggbbaa" colors
+func parseColor(s string) (pixel.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 || len(hex) == len(s) {
+		return pixel.RGBA{}, fmt.Errorf("invalid color %q, expected #rrggbb or #rrggbbaa", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	c, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return pixel.RGBA{}, fmt.Errorf("invalid color %q, expected #rrggbb or #rrggbbaa", s)
+	}
+	ch := func(shift uint) float64 { return float64(c>>shift&0xff) / 255 }
+	// pixel colors are alpha-premultiplied
+	a := ch(0)
+	return pixel.RGBA{R: ch(24) * a, G: ch(16) * a, B: ch(8) * a, A: a}, nil
+}
+
+// parseMaterial returns the material named s, "" is normal ground
+func parseMaterial(s string) (*objects.Material, error) {
+	if s == "" {
+		return objects.MaterialNormal, nil
+	}
+	m, ok := objects.Materials[s]
+	if !ok {
+		return nil, fmt.Errorf("unknown material %q", s)
+	}
+	return m, nil
+}
+
+// levelBuilders create the objects of each type found in level files
+var levelBuilders = map[string]func(o *levelObject) (objects.Object, error){
+	"platform": func(o *levelObject) (objects.Object, error) {
+		return buildPlatform(o, true)
+	},
+	// walls are platforms solid from every side
+	"wall": func(o *levelObject) (objects.Object, error) {
+		return buildPlatform(o, false)
+	},
+	// moving platforms of the size of rect, going along path
+	"moving": func(o *levelObject) (objects.Object, error) {
+		if len(o.Path) < 2 {
+			return nil, errors.New("path needs at least 2 points")
+		}
+		if o.Rect == nil {
+			return nil, errors.New("missing rect")
+		}
+		path := make([]pixel.Vec, len(o.Path))
+		for i, v := range o.Path {
+			path[i] = pixel.Vec(v)
+		}
+		m := objects.NewMovingPlatform(pixel.Rect(*o.Rect), path, 32)
+		if err := platformLook(o, &m.Color, &m.Material); err != nil {
+			return nil, err
+		}
+		return m, nil
+	},
+	"crumbling": func(o *levelObject) (objects.Object, error) {
+		if o.Rect == nil {
+			return nil, errors.New("missing rect")
+		}
+		c := objects.NewCrumblingPlatform(pixel.Rect(*o.Rect), 0.5, 0)
+		return c, platformLook(o, &c.Color, &c.Material)
+	},
+	"blinking": func(o *levelObject) (objects.Object, error) {
+		if o.Rect == nil {
+			return nil, errors.New("missing rect")
+		}
+		b := objects.NewBlinkingPlatform(pixel.Rect(*o.Rect), 2, 1, 0)
+		return b, platformLook(o, &b.Color, &b.Material)
+	},
+	// toggle platforms are turned on and off by the switches targeting one of their tags
+	"toggle": func(o *levelObject) (objects.Object, error) {
+		if o.Rect == nil {
+			return nil, errors.New("missing rect")
+		}
+		t := objects.NewTogglePlatform(pixel.Rect(*o.Rect), true)
+		return t, platformLook(o, &t.Color, &t.Material)
+	},
+	"spring": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewSpring(pixel.Vec(*o.Pos), 300), nil
+	},
+	"spikes": func(o *levelObject) (objects.Object, error) {
+		if o.Rect == nil {
+			return nil, errors.New("missing rect")
+		}
+		s := objects.NewSpikes(pixel.Rect(*o.Rect))
+		if o.Color != "" {
+			col, err := parseColor(o.Color)
+			if err != nil {
+				return nil, err
+			}
+			s.Color = col
+		}
+		return s, nil
+	},
+	// the gopher dies below the y of pos
+	"killplane": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewKillPlane(o.Pos.Y), nil
+	},
+	"checkpoint": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewCheckpoint(pixel.Vec(*o.Pos)), nil
+	},
+	"coin": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewCoin(pixel.Vec(*o.Pos)), nil
+	},
+	"gem": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewGem(pixel.Vec(*o.Pos)), nil
+	},
+	"walker": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewWalker(pixel.Vec(*o.Pos)), nil
+	},
+	"chaser": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewChaser(pixel.Vec(*o.Pos)), nil
+	},
+	"shooter": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewShooter(pixel.Vec(*o.Pos)), nil
+	},
+	"switch": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewSwitch(pixel.Vec(*o.Pos), ""), nil
+	},
+	"goal": func(o *levelObject) (objects.Object, error) {
+		if o.Pos == nil {
+			return nil, errors.New("missing pos")
+		}
+		return objects.NewGoal(pixel.Vec(*o.Pos), 18, 1.0/7), nil
+	},
+}
+
+// Level loads the level file at path and adds its objects to the scene
+func Level(path string, scene Scene) (lvl *LevelInfo, err error) {
+	// same as the animation sheet, every error tells which level failed
+	defer func() {
+		if err != nil {
+			err = errors.Wrapf(err, "error loading level %s", path)
+		}
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file levelFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		if serr, ok := err.(*json.SyntaxError); ok {
+			line := strings.Count(string(data[:serr.Offset]), "\n") + 1
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		return nil, err
+	}
+	if file.Version < 1 || file.Version > LevelVersion {
+		return nil, errors.Errorf("unsupported level version %d, expected 1 to %d", file.Version, LevelVersion)
+	}
+
+	lvl = &LevelInfo{
+		Name:       file.Name,
+		Background: pixel.RGB(0, 0, 0),
+	}
+	if file.Spawn != nil {
+		lvl.Spawn = pixel.Vec(*file.Spawn)
+	}
+	if file.Background != "" {
+		if lvl.Background, err = parseColor(file.Background); err != nil {
+			return nil, errors.Wrap(err, "background")
+		}
+	}
+	if file.Camera.Bounds != nil {
+		lvl.CameraBounds = pixel.Rect(*file.Camera.Bounds)
+	}
+
+	// build every object before touching the scene, so a broken file adds nothing
+	descs := make([]levelObject, len(file.Objects))
+	built := make([]objects.Object, len(file.Objects))
+	for i, raw := range file.Objects {
+		o := &descs[i]
+		if err := json.Unmarshal(raw, o); err != nil {
+			return nil, errors.Wrapf(err, "objects[%d]", i)
+		}
+		if built[i], err = buildObject(o); err != nil {
+			return nil, errors.Wrapf(err, "objects[%d] (%s)", i, o.Type)
+		}
+	}
+
+	for i, obj := range built {
+		o := &descs[i]
+		scene.AddObjects(obj)
+		scene.Tag(obj, o.Tags...)
+		if o.Layer != "" {
+			l, _ := objects.ParseLayer(o.Layer)
+			scene.SetLayer(obj, l, o.Z)
+		}
+	}
+	return lvl, nil
+}
+
+func buildPlatform(o *levelObject, oneWay bool) (objects.Object, error) {
+	if o.Rect == nil {
+		return nil, errors.New("missing rect")
+	}
+	p := objects.NewPlatform(pixel.Rect(*o.Rect))
+	p.OneWay = oneWay
+	if err := platformLook(o, &p.Color, &p.Material); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// platformLook sets the color, if any, and the material of a platform
+func platformLook(o *levelObject, col *color.Color, mat **objects.Material) (err error) {
+	if o.Color != "" {
+		if *col, err = parseColor(o.Color); err != nil {
+			return err
+		}
+	}
+	*mat, err = parseMaterial(o.Material)
+	return err
+}
+
+func buildObject(o *levelObject) (objects.Object, error) {
+	build, ok := levelBuilders[o.Type]
+	if !ok {
+		return nil, errors.Errorf("unknown object type %q", o.Type)
+	}
+	obj, err := build(o)
+	if err != nil {
+		return nil, err
+	}
+	if o.Layer != "" {
+		if _, ok := objects.ParseLayer(o.Layer); !ok {
+			return nil, errors.Errorf("unknown layer %q", o.Layer)
+		}
+	}
+	if c, ok := obj.(objects.Configurable); ok && o.Properties != nil {
+		if err := c.Configure(o.Properties); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
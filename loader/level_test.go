@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+// testScene records what the loaders add to it
+type testScene struct {
+	added []objects.Object
+	tags  map[objects.Object][]string
+}
+
+func (s *testScene) AddObjects(o ...objects.Object) {
+	s.added = append(s.added, o...)
+}
+
+func (s *testScene) Tag(o objects.Object, tags ...string) {
+	if s.tags == nil {
+		s.tags = make(map[objects.Object][]string)
+	}
+	s.tags[o] = append(s.tags[o], tags...)
+}
+
+func (s *testScene) SetLayer(objects.Object, objects.Layer, int) {}
+
+func TestLevel(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		level string
+		err   string // part of the error, "" for none
+		added int
+	}{
+		{"valid", `{
+			"version": 1,
+			"name": "test",
+			"spawn": [4, 8],
+			"background": "#ff0000",
+			"camera": {"bounds": [-100, -50, 100, 50]},
+			"objects": [
+				{"type": "platform", "rect": [-50, -4, 50, 0], "material": "ice", "tags": ["floor"]},
+				{"type": "coin", "pos": [0, 10], "properties": {"value": 5}}
+			]
+		}`, "", 2},
+		{"unknown version", `{"version": 2, "objects": []}`, "unsupported level version 2", 0},
+		{"unknown object type", `{"version": 1, "objects": [
+			{"type": "platform", "rect": [0, 0, 10, 2]},
+			{"type": "ladder", "pos": [0, 0]}
+		]}`, `objects[1] (ladder): unknown object type "ladder"`, 0},
+		{"malformed rect", `{"version": 1, "objects": [{"type": "platform", "rect": [0, 0, 10]}]}`,
+			"expected a rectangle", 0},
+		{"malformed color", `{"version": 1, "objects": [{"type": "platform", "rect": [0, 0, 10, 2], "color": "#ff00"}]}`,
+			`invalid color "#ff00"`, 0},
+		{"unknown material", `{"version": 1, "objects": [{"type": "platform", "rect": [0, 0, 10, 2], "material": "lava"}]}`,
+			`objects[0] (platform): unknown material "lava"`, 0},
+		{"malformed background", `{"version": 1, "background": "red", "objects": []}`, `background: invalid color "red"`, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "level.json")
+			if err := os.WriteFile(path, []byte(tc.level), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			scene := &testScene{}
+			lvl, err := Level(path, scene)
+			switch {
+			case tc.err == "" && err != nil:
+				t.Fatal(err)
+			case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+				t.Fatalf("error %v, want one with %q", err, tc.err)
+			}
+			if len(scene.added) != tc.added {
+				t.Errorf("%d objects added, want %d", len(scene.added), tc.added)
+			}
+			if tc.err != "" {
+				return
+			}
+
+			want := LevelInfo{
+				Name:         "test",
+				Spawn:        pixel.V(4, 8),
+				Background:   pixel.RGB(1, 0, 0),
+				CameraBounds: pixel.R(-100, -50, 100, 50),
+			}
+			if *lvl != want {
+				t.Errorf("level %+v, want %+v", *lvl, want)
+			}
+			if tags := scene.tags[scene.added[0]]; len(tags) != 1 || tags[0] != "floor" {
+				t.Errorf("the platform is tagged %v, want [floor]", tags)
+			}
+		})
+	}
+}
@@ -11,6 +11,19 @@ import (
 	"github.com/pkg/errors"
 )
 
+func loadPicture(path string) (pixel.Picture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return pixel.PictureDataFromImage(img), nil
+}
+
 func AnimationSheet(sheetPath, descPath string, frameWidth float64) (sheet pixel.Picture, anims map[string][]pixel.Rect, err error) {
 	// total hack, nicely format the error at the end, so I don't have to type it every time
 	defer func() {
@@ -20,16 +33,10 @@ func AnimationSheet(sheetPath, descPath string, frameWidth float64) (sheet pixel
 	}()
 
 	// open and load the spritesheet
-	sheetFile, err := os.Open(sheetPath)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer sheetFile.Close()
-	sheetImg, _, err := image.Decode(sheetFile)
+	sheet, err = loadPicture(sheetPath)
 	if err != nil {
 		return nil, nil, err
 	}
-	sheet = pixel.PictureDataFromImage(sheetImg)
 
 	// create a slice of frames inside the spritesheet
 	var frames []pixel.Rect
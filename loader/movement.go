@@ -0,0 +1,73 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+// Movement loads a movement file, a JSON object with the fields of objects.Movement:
+//
+//	{"gravity": -512, "runSpeed": 64, "jumpSpeed": 192, "width": 12, "height": 14}
+//
+// Missing fields keep their objects.DefaultMovement value.
+func Movement(path string) (m objects.Movement, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrapf(err, "error loading movement %s", path)
+		}
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	m = objects.DefaultMovement()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// a typo in a field name should not silently keep the default
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return m, err
+	}
+	return m, m.Validate()
+}
+
+// FileWatcher tells when a file was modified, by polling its modification time
+type FileWatcher struct {
+	path    string
+	every   time.Duration
+	checked time.Time
+	modTime time.Time
+}
+
+// WatchFile watches the file at path, checking it at most once every interval
+func WatchFile(path string, every time.Duration) *FileWatcher {
+	w := &FileWatcher{path: path, every: every, checked: time.Now()}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+	}
+	return w
+}
+
+func (w *FileWatcher) Path() string {
+	return w.path
+}
+
+// Changed reports whether the file was modified since the last change, call it every frame.
+// A file which can't be read is not a change, editors often replace files while saving them.
+func (w *FileWatcher) Changed() bool {
+	if time.Since(w.checked) < w.every {
+		return false
+	}
+	w.checked = time.Now()
+	info, err := os.Stat(w.path)
+	if err != nil || info.ModTime().Equal(w.modTime) {
+		return false
+	}
+	w.modTime = info.ModTime()
+	return true
+}
@@ -0,0 +1,51 @@
+package loader
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+// Stats loads the best stats of every completed level from the stats file at path,
+// a missing file has no stats yet
+func Stats(path string) (map[string]objects.LevelStats, error) {
+	stats := make(map[string]objects.LevelStats)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading stats %s", path)
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, errors.Wrapf(err, "error loading stats %s", path)
+	}
+	return stats, nil
+}
+
+// SaveStats records the stats of a completed level in the stats file at path,
+// unless the level was already done better: collecting more, then faster
+func SaveStats(path string, st objects.LevelStats) error {
+	stats, err := Stats(path)
+	if err != nil {
+		return err
+	}
+	if best, ok := stats[st.Level]; ok && !better(st, best) {
+		return nil
+	}
+	stats[st.Level] = st
+	data, err := json.MarshalIndent(stats, "", "\t")
+	if err != nil {
+		return errors.Wrapf(err, "error saving stats %s", path)
+	}
+	return errors.Wrapf(os.WriteFile(path, data, 0644), "error saving stats %s", path)
+}
+
+func better(a, b objects.LevelStats) bool {
+	if a.Collected != b.Collected {
+		return a.Collected > b.Collected
+	}
+	return a.Time < b.Time
+}
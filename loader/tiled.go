@@ -0,0 +1,623 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+// the high bits of a tile gid are the flip flags
+const tiledGIDMask = 0x0fffffff
+
+// tiledMap is the part of a Tiled map the importer uses, shared by the TMX and TMJ formats
+type tiledMap struct {
+	Orientation     string
+	Width, Height   int // in tiles
+	TileW, TileH    float64
+	Infinite        bool
+	BackgroundColor string
+	Properties      objects.Properties
+	Layers          []tiledLayer
+	Tilesets        []tiledTileset
+}
+
+// tiledTileset is a tileset embedded in the map, external tilesets have no image
+type tiledTileset struct {
+	FirstGID uint32 `xml:"firstgid,attr" json:"firstgid"`
+	Image    string `xml:"-" json:"image"`
+	XMLImage struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image" json:"-"`
+}
+
+type tiledLayer struct {
+	Name       string
+	Tiles      []uint32 // gids, row by row from the top, nil for object layers
+	Width      int
+	Height     int
+	Objects    []tiledObject
+	Properties objects.Properties
+}
+
+type tiledObject struct {
+	Name       string
+	Type       string
+	X, Y, W, H float64
+	Point      bool
+	GID        uint32
+	Properties objects.Properties
+}
+
+// tiledProperty is a custom property as written in both TMX and TMJ files
+type tiledProperty struct {
+	Name     string      `xml:"name,attr" json:"name"`
+	Type     string      `xml:"type,attr" json:"type"`
+	Value    interface{} `xml:"-" json:"value"`
+	XMLValue string      `xml:"value,attr" json:"-"`
+	Text     string      `xml:",chardata" json:"-"`
+}
+
+// tiledColor converts the #aarrggbb (or #rrggbb) colors of Tiled into #rrggbbaa
+func tiledColor(c string) string {
+	if len(c) == 9 && c[0] == '#' {
+		return "#" + c[3:] + c[1:3]
+	}
+	return c
+}
+
+func tiledProperties(props []tiledProperty) (objects.Properties, error) {
+	p := make(objects.Properties, len(props))
+	for _, prop := range props {
+		v := prop.Value
+		if v == nil {
+			// TMX values are attributes, or the text of multiline strings
+			s := prop.XMLValue
+			if s == "" {
+				s = prop.Text
+			}
+			v = s
+			switch prop.Type {
+			case "int", "float", "object":
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, errors.Errorf("property %q: invalid %s %q", prop.Name, prop.Type, s)
+				}
+				v = f
+			case "bool":
+				v = s == "true"
+			}
+		}
+		if prop.Type == "color" {
+			if s, ok := v.(string); ok {
+				v = tiledColor(s)
+			}
+		}
+		p[prop.Name] = v
+	}
+	return p, nil
+}
+
+// decodeTiledData decodes the csv or base64 (optionally zlib or gzip compressed) tile data
+func decodeTiledData(data, encoding, compression string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, f := range strings.Split(data, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, errors.Errorf("invalid tile %q", f)
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.Errorf("unsupported compression %q", compression)
+		}
+		raw, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	}
+	return nil, errors.Errorf("unsupported encoding %q", encoding)
+}
+
+// TMX layout
+
+type tmxMap struct {
+	Orientation     string          `xml:"orientation,attr"`
+	Width           int             `xml:"width,attr"`
+	Height          int             `xml:"height,attr"`
+	TileWidth       float64         `xml:"tilewidth,attr"`
+	TileHeight      float64         `xml:"tileheight,attr"`
+	Infinite        int             `xml:"infinite,attr"`
+	BackgroundColor string          `xml:"backgroundcolor,attr"`
+	Properties      []tiledProperty `xml:"properties>property"`
+	Tilesets        []tiledTileset  `xml:"tileset"`
+	tmxGroup
+}
+
+type tmxGroup struct {
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+	Groups       []tmxGroup       `xml:"group"`
+}
+
+type tmxLayer struct {
+	Name       string          `xml:"name,attr"`
+	Width      int             `xml:"width,attr"`
+	Height     int             `xml:"height,attr"`
+	Properties []tiledProperty `xml:"properties>property"`
+	Data       struct {
+		Encoding    string `xml:"encoding,attr"`
+		Compression string `xml:"compression,attr"`
+		Text        string `xml:",chardata"`
+		Tiles       []struct {
+			GID uint32 `xml:"gid,attr"`
+		} `xml:"tile"`
+	} `xml:"data"`
+}
+
+type tmxObjectGroup struct {
+	Name       string          `xml:"name,attr"`
+	Properties []tiledProperty `xml:"properties>property"`
+	Objects    []struct {
+		Name       string          `xml:"name,attr"`
+		Type       string          `xml:"type,attr"`
+		Class      string          `xml:"class,attr"`
+		X          float64         `xml:"x,attr"`
+		Y          float64         `xml:"y,attr"`
+		Width      float64         `xml:"width,attr"`
+		Height     float64         `xml:"height,attr"`
+		GID        uint32          `xml:"gid,attr"`
+		Point      *struct{}       `xml:"point"`
+		Properties []tiledProperty `xml:"properties>property"`
+	} `xml:"object"`
+}
+
+func (g *tmxGroup) layers() ([]tiledLayer, error) {
+	var layers []tiledLayer
+	for i, l := range g.Layers {
+		tl := tiledLayer{Name: l.Name, Width: l.Width, Height: l.Height}
+		var err error
+		if l.Data.Encoding == "" {
+			// no encoding, one <tile> element per tile
+			for _, t := range l.Data.Tiles {
+				tl.Tiles = append(tl.Tiles, t.GID)
+			}
+		} else if tl.Tiles, err = decodeTiledData(l.Data.Text, l.Data.Encoding, l.Data.Compression); err != nil {
+			return nil, errors.Wrapf(err, "layer[%d] (%s)", i, l.Name)
+		}
+		if tl.Properties, err = tiledProperties(l.Properties); err != nil {
+			return nil, errors.Wrapf(err, "layer[%d] (%s)", i, l.Name)
+		}
+		layers = append(layers, tl)
+	}
+	for i, og := range g.ObjectGroups {
+		tl := tiledLayer{Name: og.Name}
+		var err error
+		if tl.Properties, err = tiledProperties(og.Properties); err != nil {
+			return nil, errors.Wrapf(err, "objectgroup[%d] (%s)", i, og.Name)
+		}
+		for j, o := range og.Objects {
+			to := tiledObject{
+				Name:  o.Name,
+				Type:  o.Type,
+				X:     o.X,
+				Y:     o.Y,
+				W:     o.Width,
+				H:     o.Height,
+				Point: o.Point != nil,
+				GID:   o.GID,
+			}
+			if to.Type == "" {
+				to.Type = o.Class
+			}
+			if to.Properties, err = tiledProperties(o.Properties); err != nil {
+				return nil, errors.Wrapf(err, "objectgroup[%d] (%s): object[%d]", i, og.Name, j)
+			}
+			tl.Objects = append(tl.Objects, to)
+		}
+		layers = append(layers, tl)
+	}
+	for i := range g.Groups {
+		sub, err := g.Groups[i].layers()
+		if err != nil {
+			return nil, errors.Wrapf(err, "group[%d]", i)
+		}
+		layers = append(layers, sub...)
+	}
+	return layers, nil
+}
+
+func parseTMX(data []byte) (*tiledMap, error) {
+	var m tmxMap
+	if err := xml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	layers, err := m.tmxGroup.layers()
+	if err != nil {
+		return nil, err
+	}
+	props, err := tiledProperties(m.Properties)
+	if err != nil {
+		return nil, err
+	}
+	for i := range m.Tilesets {
+		m.Tilesets[i].Image = m.Tilesets[i].XMLImage.Source
+	}
+	return &tiledMap{
+		Orientation:     m.Orientation,
+		Width:           m.Width,
+		Height:          m.Height,
+		TileW:           m.TileWidth,
+		TileH:           m.TileHeight,
+		Infinite:        m.Infinite != 0,
+		BackgroundColor: m.BackgroundColor,
+		Properties:      props,
+		Layers:          layers,
+		Tilesets:        m.Tilesets,
+	}, nil
+}
+
+// TMJ layout
+
+type tmjMap struct {
+	Orientation     string          `json:"orientation"`
+	Width           int             `json:"width"`
+	Height          int             `json:"height"`
+	TileWidth       float64         `json:"tilewidth"`
+	TileHeight      float64         `json:"tileheight"`
+	Infinite        bool            `json:"infinite"`
+	BackgroundColor string          `json:"backgroundcolor"`
+	Properties      []tiledProperty `json:"properties"`
+	Tilesets        []tiledTileset  `json:"tilesets"`
+	Layers          []tmjLayer      `json:"layers"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Properties  []tiledProperty `json:"properties"`
+	Layers      []tmjLayer      `json:"layers"`
+	Objects     []struct {
+		Name       string          `json:"name"`
+		Type       string          `json:"type"`
+		Class      string          `json:"class"`
+		X          float64         `json:"x"`
+		Y          float64         `json:"y"`
+		Width      float64         `json:"width"`
+		Height     float64         `json:"height"`
+		GID        uint32          `json:"gid"`
+		Point      bool            `json:"point"`
+		Properties []tiledProperty `json:"properties"`
+	} `json:"objects"`
+}
+
+func tmjLayers(layers []tmjLayer) ([]tiledLayer, error) {
+	var out []tiledLayer
+	for i, l := range layers {
+		tl := tiledLayer{Name: l.Name, Width: l.Width, Height: l.Height}
+		var err error
+		if tl.Properties, err = tiledProperties(l.Properties); err != nil {
+			return nil, errors.Wrapf(err, "layers[%d] (%s)", i, l.Name)
+		}
+		switch l.Type {
+		case "tilelayer":
+			if l.Encoding == "base64" {
+				var s string
+				if err := json.Unmarshal(l.Data, &s); err != nil {
+					return nil, errors.Wrapf(err, "layers[%d] (%s)", i, l.Name)
+				}
+				tl.Tiles, err = decodeTiledData(s, l.Encoding, l.Compression)
+			} else {
+				err = json.Unmarshal(l.Data, &tl.Tiles)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "layers[%d] (%s)", i, l.Name)
+			}
+		case "objectgroup":
+			for j, o := range l.Objects {
+				to := tiledObject{
+					Name:  o.Name,
+					Type:  o.Type,
+					X:     o.X,
+					Y:     o.Y,
+					W:     o.Width,
+					H:     o.Height,
+					Point: o.Point,
+					GID:   o.GID,
+				}
+				if to.Type == "" {
+					to.Type = o.Class
+				}
+				if to.Properties, err = tiledProperties(o.Properties); err != nil {
+					return nil, errors.Wrapf(err, "layers[%d] (%s): objects[%d]", i, l.Name, j)
+				}
+				tl.Objects = append(tl.Objects, to)
+			}
+		case "group":
+			sub, err := tmjLayers(l.Layers)
+			if err != nil {
+				return nil, errors.Wrapf(err, "layers[%d] (%s)", i, l.Name)
+			}
+			out = append(out, sub...)
+			continue
+		default:
+			// image layers have nothing to import
+			continue
+		}
+		out = append(out, tl)
+	}
+	return out, nil
+}
+
+func parseTMJ(data []byte) (*tiledMap, error) {
+	var m tmjMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	layers, err := tmjLayers(m.Layers)
+	if err != nil {
+		return nil, err
+	}
+	props, err := tiledProperties(m.Properties)
+	if err != nil {
+		return nil, err
+	}
+	return &tiledMap{
+		Orientation:     m.Orientation,
+		Width:           m.Width,
+		Height:          m.Height,
+		TileW:           m.TileWidth,
+		TileH:           m.TileHeight,
+		Infinite:        m.Infinite,
+		BackgroundColor: m.BackgroundColor,
+		Properties:      props,
+		Layers:          layers,
+		Tilesets:        m.Tilesets,
+	}, nil
+}
+
+// tilesetOf returns the tileset the gid belongs to, the one with the highest first gid not above it
+func tilesetOf(tilesets []tiledTileset, gid uint32) *tiledTileset {
+	var ts *tiledTileset
+	for i := range tilesets {
+		if tilesets[i].FirstGID <= gid && (ts == nil || tilesets[i].FirstGID > ts.FirstGID) {
+			ts = &tilesets[i]
+		}
+	}
+	return ts
+}
+
+func nonEmpty(s ...string) []string {
+	var out []string
+	for _, v := range s {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Tiled imports an orthogonal Tiled map (.tmx or .tmj) and adds its content to the scene.
+//
+// Tile layers become tilemaps drawn with the tileset having an image, only one tileset may have one.
+// Layers with a "solid" property set to false become decorations, drawn behind the terrain without colliding.
+// Rectangle objects become platforms, or objects of their type (class) like in level files,
+// point objects of type "spawn" and "goal" set the spawn point and place the goal.
+// Custom properties are given to the objects as their properties, the "color" property sets their color
+// and the "material" property (normal, ice or mud) the ground they are made of, for tile layers too.
+// The y axis is flipped, the top left corner of the map is at (0, 0).
+func Tiled(path string, scene Scene) (lvl *LevelInfo, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrapf(err, "error loading tiled map %s", path)
+		}
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m *tiledMap
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx", ".xml":
+		m, err = parseTMX(data)
+	case ".tmj", ".json":
+		m, err = parseTMJ(data)
+	default:
+		return nil, errors.Errorf("unknown map format %q, expected .tmx or .tmj", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Orientation != "orthogonal" {
+		return nil, errors.Errorf("unsupported orientation %q, only orthogonal maps are supported", m.Orientation)
+	}
+	if m.Infinite {
+		return nil, errors.New("infinite maps are not supported")
+	}
+
+	lvl = &LevelInfo{
+		Background:   pixel.RGB(0, 0, 0),
+		CameraBounds: pixel.R(0, -float64(m.Height)*m.TileH, float64(m.Width)*m.TileW, 0),
+	}
+	if lvl.Name, err = m.Properties.String("name", strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))); err != nil {
+		return nil, err
+	}
+	if m.BackgroundColor != "" {
+		if lvl.Background, err = parseColor(tiledColor(m.BackgroundColor)); err != nil {
+			return nil, errors.Wrap(err, "backgroundcolor")
+		}
+	}
+
+	// the tileset with an image draws the tile layers, a tilemap draws from a single picture
+	var tileset pixel.Picture
+	var drawn *tiledTileset
+	for i := range m.Tilesets {
+		ts := &m.Tilesets[i]
+		if ts.Image == "" {
+			continue
+		}
+		if drawn != nil {
+			return nil, errors.Errorf("tileset[%d]: only one tileset with an image is supported", i)
+		}
+		if tileset, err = loadPicture(filepath.Join(filepath.Dir(path), ts.Image)); err != nil {
+			return nil, errors.Wrapf(err, "tileset[%d]", i)
+		}
+		drawn = ts
+	}
+
+	// tile layers become tilemaps, objects are converted into level objects to be built like in level files
+	var tilemaps []objects.Object
+	var tilemapTags [][]string
+	var descs []levelObject
+	var names []string // where each object comes from, for the errors
+	for i, l := range m.Layers {
+		if l.Tiles != nil {
+			name := fmt.Sprintf("layer[%d] (%s)", i, l.Name)
+			solid, err := l.Properties.Bool("solid", true)
+			if err != nil {
+				return nil, errors.Wrap(err, name)
+			}
+			if len(l.Tiles) != l.Width*l.Height {
+				return nil, errors.Errorf("%s: %d tiles for a %dx%d layer", name, len(l.Tiles), l.Width, l.Height)
+			}
+			tm := objects.NewTilemap(pixel.V(0, -float64(l.Height)*m.TileH), pixel.V(m.TileW, m.TileH), l.Width, l.Height, tileset)
+			tm.Decoration = !solid
+			for j, gid := range l.Tiles {
+				gid &= tiledGIDMask
+				if gid == 0 {
+					continue
+				}
+				// Tiled rows go down, tilemap rows go up
+				id := int(gid)
+				if drawn != nil {
+					if tilesetOf(m.Tilesets, gid) != drawn {
+						return nil, errors.Errorf("%s: tile %d is not in the tileset with the image", name, gid)
+					}
+					id = int(gid-drawn.FirstGID) + 1
+				}
+				tm.SetTile(j%l.Width, l.Height-1-j/l.Width, id)
+			}
+			if c, err := l.Properties.String("color", ""); err != nil {
+				return nil, errors.Wrap(err, name)
+			} else if c != "" {
+				if tm.Color, err = parseColor(c); err != nil {
+					return nil, errors.Wrap(err, name)
+				}
+			}
+			if mat, err := l.Properties.String("material", ""); err != nil {
+				return nil, errors.Wrap(err, name)
+			} else if tm.Material, err = parseMaterial(mat); err != nil {
+				return nil, errors.Wrap(err, name)
+			}
+			tilemaps = append(tilemaps, tm)
+			if solid {
+				tilemapTags = append(tilemapTags, nonEmpty("terrain", l.Name))
+			} else {
+				tilemapTags = append(tilemapTags, nonEmpty("decoration", l.Name))
+			}
+			continue
+		}
+
+		for j, o := range l.Objects {
+			name := fmt.Sprintf("layer[%d] (%s): object[%d] (%s)", i, l.Name, j, o.Name)
+			d := levelObject{
+				Type:       o.Type,
+				Tags:       nonEmpty(l.Name, o.Name),
+				Properties: o.Properties,
+			}
+			if d.Color, err = o.Properties.String("color", ""); err != nil {
+				return nil, errors.Wrap(err, name)
+			}
+			if d.Material, err = o.Properties.String("material", ""); err != nil {
+				return nil, errors.Wrap(err, name)
+			}
+
+			if o.Point || o.W == 0 && o.H == 0 {
+				pos := jsonVec(pixel.V(o.X, -o.Y))
+				d.Pos = &pos
+				if d.Type == "spawn" {
+					lvl.Spawn = pixel.Vec(pos)
+					continue
+				}
+			} else {
+				// tile objects are anchored at their bottom left corner, the others at their top left corner
+				y := o.Y
+				if o.GID != 0 {
+					y -= o.H
+				}
+				rect := jsonRect(pixel.R(o.X, -y-o.H, o.X+o.W, -y))
+				center := jsonVec(pixel.Rect(rect).Center())
+				d.Rect, d.Pos = &rect, &center
+				if d.Type == "" {
+					d.Type = "platform"
+				}
+			}
+			descs = append(descs, d)
+			names = append(names, name)
+		}
+	}
+
+	// build every object before touching the scene, so a broken map adds nothing
+	built := make([]objects.Object, len(descs))
+	for i := range descs {
+		if built[i], err = buildObject(&descs[i]); err != nil {
+			return nil, errors.Wrap(err, names[i])
+		}
+	}
+	for i, tm := range tilemaps {
+		scene.AddObjects(tm)
+		scene.Tag(tm, tilemapTags[i]...)
+	}
+	for i, obj := range built {
+		scene.AddObjects(obj)
+		scene.Tag(obj, descs[i].Tags...)
+	}
+	return lvl, nil
+}
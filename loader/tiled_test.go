@@ -0,0 +1,68 @@
+package loader
+
+import (
+	_ "image/png"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+func TestTiled(t *testing.T) {
+	scene := &testScene{}
+	lvl, err := Tiled("testdata/map.tmj", scene)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvl.Name != "fixture" || lvl.Spawn != pixel.V(4, -8) || lvl.CameraBounds != pixel.R(0, -16, 32, 0) {
+		t.Errorf("level %+v, want the fixture spawning at (4, -8) in a 32x16 map", *lvl)
+	}
+	if len(scene.added) != 3 {
+		t.Fatalf("%d objects added, want the ground, the bushes and the goal", len(scene.added))
+	}
+
+	type tiles interface {
+		objects.Solid
+		Tile(x, y int) int
+	}
+	ground, bushes := scene.added[0].(tiles), scene.added[1].(tiles)
+	if tags := scene.tags[scene.added[0]]; !reflect.DeepEqual(tags, []string{"terrain", "ground"}) {
+		t.Errorf("the ground is tagged %v", tags)
+	}
+	if tags := scene.tags[scene.added[1]]; !reflect.DeepEqual(tags, []string{"decoration", "bushes"}) {
+		t.Errorf("the bushes are tagged %v", tags)
+	}
+	// the tiles of the tileset with the image start at its first gid, 3
+	if a, b := ground.Tile(0, 0), ground.Tile(1, 0); a != 1 || b != 2 {
+		t.Errorf("the bottom row of the ground starts with the tiles %d and %d, want 1 and 2", a, b)
+	}
+	if len(ground.AppendSurfaces(nil)) == 0 {
+		t.Error("the ground has no surfaces")
+	}
+	if bushes.Tile(1, 1) != 2 {
+		t.Errorf("the bush tile is %d, want 2", bushes.Tile(1, 1))
+	}
+	if s := bushes.AppendSurfaces(nil); len(s) != 0 {
+		t.Errorf("the layer set not solid has the surfaces %v", s)
+	}
+}
+
+func TestTiledErrors(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		err  string
+	}{
+		{"testdata/two-images.tmj", "tileset[1]: only one tileset with an image is supported"},
+		{"testdata/foreign-tile.tmj", "layer[0] (ground): tile 1 is not in the tileset with the image"},
+	} {
+		scene := &testScene{}
+		if _, err := Tiled(tc.path, scene); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error %v, want one with %q", tc.path, err, tc.err)
+		}
+		if len(scene.added) != 0 {
+			t.Errorf("%s: %d objects added from a broken map", tc.path, len(scene.added))
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"math"
+	"path/filepath"
+	"strings"
 	"time"
 
 	_ "image/png"
@@ -9,12 +13,39 @@ import (
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
-	"github.com/unknownTravelers/3D-jump-infinite/controls"
+	"github.com/faiface/pixel/text"
+	"github.com/unknownTravelers/3D-jump-infinite/camera"
+	"github.com/unknownTravelers/3D-jump-infinite/controls/keyboard"
 	"github.com/unknownTravelers/3D-jump-infinite/loader"
 	"github.com/unknownTravelers/3D-jump-infinite/objects"
 	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
 )
 
+var (
+	levelPath = flag.String("level", "level.json", "level to play, .tmx and .tmj Tiled maps are imported")
+	infinite  = flag.Bool("infinite", false, "play an endless generated level instead")
+	seed      = flag.Int64("seed", time.Now().UnixNano(), "seed of the endless level")
+	movePath  = flag.String("movement", "gopher.json", "movement of the gopher, reloaded when the file changes")
+	statsPath = flag.String("stats", "stats.json", "file keeping the best stats of the completed levels")
+	abilities = flag.String("abilities", "", "comma separated abilities the gopher starts with: "+strings.Join(objects.AbilityNames(), ", "))
+)
+
+// drawHUD writes the stats of the level in the top left corner of the window
+func drawHUD(win *pixelgl.Window, hud *text.Text) {
+	st := objects.Game.Stats()
+	hud.Clear()
+	fmt.Fprintf(hud, "score %d  collected %d/%d  deaths %d  time %.1fs", objects.Game.Score, st.Collected, st.Total, st.Deaths, st.Time)
+	if st.Completed {
+		fmt.Fprint(hud, "\nlevel complete!")
+	}
+	win.SetMatrix(pixel.IM)
+	m := pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(8, win.Bounds().H()-8-2*hud.LineHeight))
+	// a shadow keeps the text readable on light backgrounds
+	hud.DrawColorMask(win, m.Moved(pixel.V(2, -2)), colornames.Black)
+	hud.Draw(win, m)
+}
+
 func run() {
 	sheet, anims, err := loader.AnimationSheet("sheet.png", "sheet.csv", 12)
 	if err != nil {
@@ -33,49 +64,106 @@ func run() {
 
 	// Create level
 	scene := objects.NewScene()
-
-	// hardcoded level
-	platforms := []objects.Object{
-		objects.NewPlatform(pixel.R(-50, -34, 50, -32)),
-		objects.NewPlatform(pixel.R(20, 0, 70, 2)),
-		objects.NewPlatform(pixel.R(-100, 10, -50, 12)),
-		objects.NewPlatform(pixel.R(120, -22, 140, -20)),
-		objects.NewPlatform(pixel.R(120, -72, 140, -70)),
-		objects.NewPlatform(pixel.R(120, -122, 140, -120)),
-		objects.NewPlatform(pixel.R(-100, -152, 100, -150)),
-		objects.NewPlatform(pixel.R(-150, -127, -140, -125)),
-		objects.NewPlatform(pixel.R(-180, -97, -170, -95)),
-		objects.NewPlatform(pixel.R(-150, -67, -140, -65)),
-		objects.NewPlatform(pixel.R(-180, -37, -170, -35)),
-		objects.NewPlatform(pixel.R(-150, -7, -140, -5)),
+	var lvl *loader.LevelInfo
+	switch {
+	case *infinite:
+		lvl = &loader.LevelInfo{Name: "infinite", Background: pixel.RGB(0, 0, 0)}
+	case filepath.Ext(*levelPath) == ".tmx" || filepath.Ext(*levelPath) == ".tmj":
+		lvl, err = loader.Tiled(*levelPath, scene)
+	default:
+		lvl, err = loader.Level(*levelPath, scene)
+	}
+	if err != nil {
+		panic(err)
 	}
-	gol := objects.NewGoal(pixel.V(-75, 40), 18, 1.0/7)
-
-	scene.AddObjects(platforms...)
-	scene.AddObjects(gol)
 
 	// Creating player & add it to level
-	goph := objects.NewGopher(sheet, anims)
+	move, err := loader.Movement(*movePath)
+	if err != nil {
+		panic(err)
+	}
+	goph, err := objects.NewGopher(sheet, anims, objects.WithMovement(move))
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range strings.Split(*abilities, ",") {
+		if name == "" {
+			continue
+		}
+		newAbility, ok := objects.Abilities[name]
+		if !ok {
+			panic(fmt.Sprintf("unknown ability %q", name))
+		}
+		goph.Grant(newAbility())
+	}
+	goph.SetSpawn(lvl.Spawn)
+	goph.Respawn()
 	scene.AddObjects(goph)
 
+	// the generator builds the level around the gopher and kills it when it falls,
+	// else falling under the camera or far below the level kills it
+	switch {
+	case *infinite:
+		scene.AddObjects(objects.NewGenerator(*seed, goph))
+	case lvl.CameraBounds != pixel.ZR:
+		scene.AddObjects(objects.NewKillPlane(lvl.CameraBounds.Min.Y - 64))
+	default:
+		scene.AddObjects(objects.KillPlaneBelow(scene, 200))
+	}
+
 	objects.Game.AddScenes(scene)
+	objects.Game.StartLevel(lvl.Name)
+	objects.Game.OnComplete = func(st objects.LevelStats) {
+		if err := loader.SaveStats(*statsPath, st); err != nil {
+			fmt.Println(err)
+		}
+	}
 
 	// Creating window
 	canvas := pixelgl.NewCanvas(pixel.R(-160/2, -120/2, 160/2, 120/2))
 	imd := imdraw.New(sheet)
 	imd.Precision = 32
 
-	camPos := pixel.ZV
+	// the score, collectibles and time of the level drawn over the window
+	hud := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+
+	// the camera follows the gopher, looking ahead where it runs
+	cam := camera.New(canvas.Bounds().Size(), goph)
+	cam.Deadzone = pixel.R(-12, -16, 12, 16)
+	cam.Bounds = lvl.CameraBounds
+	cam.Lookahead = 0.3
+	cam.PlatformSnap = true
+
+	// shake the screen on hard landings
+	fx := camera.NewEffects()
+	goph.Phys.OnLand = func(speed float64) {
+		const hardLanding = 250
+		if speed > hardLanding {
+			fx.AddTrauma((speed - hardLanding) / 300)
+			fx.Punch(0.04, 0.2, camera.Quadratic)
+		}
+	}
+	goph.OnDeath = func() {
+		fx.AddTrauma(0.5)
+	}
+
+	// objects further than a screen away from the view are not updated
+	scene.SetActivityRadius(canvas.Bounds().W())
+
+	// tweak the movement while playing, a broken file keeps the previous movement
+	moveWatch := loader.WatchFile(*movePath, time.Second/2)
 
 	last := time.Now()
+	lastStats := last
 	for !win.Closed() {
 		dt := time.Since(last).Seconds()
 		last = time.Now()
 
-		// lerp the camera position towards the gopher
-		camPos = pixel.Lerp(camPos, goph.Phys.Rect.Center(), 1-math.Pow(1.0/128, dt))
-		cam := pixel.IM.Moved(camPos.Scaled(-1))
-		canvas.SetMatrix(cam)
+		// move the camera towards the gopher
+		cam.Update(dt)
+		fx.Update(dt)
+		canvas.SetMatrix(fx.Apply(cam.Matrix()))
+		scene.SetView(fx.View(cam.Matrix(), cam.View()))
 
 		// slow motion with tab
 		if win.Pressed(pixelgl.KeyTab) {
@@ -84,21 +172,56 @@ func run() {
 
 		// restart the level on pressing enter
 		if win.JustPressed(pixelgl.KeyEnter) {
-			goph.Phys.Rect = goph.Phys.Rect.Moved(goph.Phys.Rect.Center().Scaled(-1))
-			goph.Phys.Vel = pixel.ZV
+			goph.SetSpawn(lvl.Spawn)
+			goph.Respawn()
+			objects.Game.StartLevel(lvl.Name)
+		}
+
+		if moveWatch.Changed() {
+			move, err := loader.Movement(*movePath)
+			if err == nil {
+				err = goph.SetMovement(move)
+			}
+			if err != nil {
+				fmt.Println(err)
+			}
+		}
+
+		// turn the screen shake on and off
+		if win.JustPressed(pixelgl.KeyF10) {
+			camera.EffectsEnabled = !camera.EffectsEnabled
+			fx.Reset()
+		}
+
+		// toggle the draw layers with F1-F5 for debugging
+		for i, key := range []pixelgl.Button{pixelgl.KeyF1, pixelgl.KeyF2, pixelgl.KeyF3, pixelgl.KeyF4, pixelgl.KeyF5} {
+			if win.JustPressed(key) {
+				scene.ToggleLayer(objects.LayerBackground + objects.Layer(i))
+			}
+		}
+
+		// grant and revoke the abilities with 1, 2, 3...
+		for i, name := range objects.AbilityNames() {
+			if !win.JustPressed(pixelgl.Key1 + pixelgl.Button(i)) {
+				continue
+			}
+			if _, ok := goph.Ability(name); ok {
+				goph.Revoke(name)
+			} else {
+				goph.Grant(objects.Abilities[name]())
+			}
 		}
 
 		// control the gopher with keys
-		controls.Update(win)
+		keyboard.Update(win)
 
 		// update the physics and animation
 		scene.Update(dt)
 
-		// draw the scene to the canvas using IMDraw
-		canvas.Clear(colornames.Black)
+		// draw the scene to the canvas using IMDraw, and the batches of the tiles in between
+		canvas.Clear(lvl.Background)
 		imd.Clear()
-		scene.Draw(imd)
-		imd.Draw(canvas)
+		scene.DrawTo(canvas, imd)
 
 		// stretch the canvas to the window
 		win.Clear(colornames.White)
@@ -109,10 +232,20 @@ func run() {
 			),
 		).Moved(win.Bounds().Center()))
 		canvas.Draw(win, pixel.IM.Moved(canvas.Bounds().Center()))
+		drawHUD(win, hud)
 		win.Update()
+
+		// show the culling counters in the title
+		if time.Since(lastStats) > time.Second {
+			lastStats = time.Now()
+			st := scene.Stats()
+			win.SetTitle(fmt.Sprintf("%s | updated %d asleep %d drawn %d culled %d",
+				cfg.Title, st.Updated, st.Asleep, st.Drawn, st.Culled))
+		}
 	}
 }
 
 func main() {
+	flag.Parse()
 	pixelgl.Run(run)
 }
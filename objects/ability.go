@@ -0,0 +1,282 @@
+package objects
+
+import (
+	"sort"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/controls"
+)
+
+// Ability is a movement the gopher can be granted, hooked into each step of its physics
+type Ability interface {
+	Name() string
+	// BeforeMove is called after the controls and gravity changed the velocity, before the gopher moves
+	BeforeMove(gp *gopherPhys, dt float64)
+	// AfterMove is called once the gopher moved, collided and maybe jumped
+	AfterMove(gp *gopherPhys, dt float64)
+	// Clone copies the ability with its state, simulations of the gopher must not change the real one
+	Clone() Ability
+}
+
+// AirJumper abilities let the gopher jump in the air, the bot asks them before trying a jump
+type AirJumper interface {
+	CanAirJump(gp *gopherPhys) bool
+}
+
+// Resetter abilities forget what they were doing when the gopher respawns, a dive or a dash must not go on
+type Resetter interface {
+	Reset()
+}
+
+// Abilities create the abilities by name
+var Abilities = map[string]func() Ability{
+	"doubleJump":  func() Ability { return NewDoubleJump() },
+	"airDash":     func() Ability { return NewAirDash() },
+	"groundPound": func() Ability { return NewGroundPound() },
+	"blaster":     func() Ability { return NewBlaster() },
+}
+
+// AbilityNames returns the names of Abilities, sorted
+func AbilityNames() []string {
+	var names []string
+	for name := range Abilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Grant gives the ability to the gopher, replacing the ability of the same name
+func (ga *gopherAnim) Grant(a Ability) {
+	ga.Revoke(a.Name())
+	ga.Phys.abilities = append(ga.Phys.abilities, a)
+}
+
+func (ga *gopherAnim) Revoke(name string) {
+	abilities := ga.Phys.abilities[:0]
+	for _, a := range ga.Phys.abilities {
+		if a.Name() != name {
+			abilities = append(abilities, a)
+		}
+	}
+	ga.Phys.abilities = abilities
+}
+
+func (ga *gopherAnim) Ability(name string) (Ability, bool) {
+	for _, a := range ga.Phys.abilities {
+		if a.Name() == name {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// clone copies the physics of the gopher to simulate it, with copies of its abilities.
+// The copy does not call OnLand nor tell the surfaces it stands on them.
+func (gp *gopherPhys) clone() gopherPhys {
+	c := *gp
+	c.OnLand = nil
+	c.simulated = true
+	c.abilities = make([]Ability, len(gp.abilities))
+	for i, a := range gp.abilities {
+		c.abilities[i] = a.Clone()
+	}
+	return c
+}
+
+// facing returns the direction the gopher wants to go, else the direction it goes, else right
+func (gp *gopherPhys) facing() float64 {
+	switch {
+	case controls.Controls.X < 0 || controls.Controls.X == 0 && gp.Vel.X < 0:
+		return -1
+	default:
+		return +1
+	}
+}
+
+// doubleJump jumps again in the air, Charges times until the gopher lands or touches a wall
+type doubleJump struct {
+	Charges int
+	Speed   float64
+
+	left int
+}
+
+func (d *doubleJump) Name() string { return "doubleJump" }
+
+func (d *doubleJump) BeforeMove(gp *gopherPhys, dt float64) {}
+
+func (d *doubleJump) AfterMove(gp *gopherPhys, dt float64) {
+	if gp.ground || gp.wall != 0 {
+		d.left = d.Charges
+		return
+	}
+	// only a jump pressed now, a buffered one is kept for the landing
+	if controls.Controls.Y > 0 && gp.buffered > 0 && !gp.mayJump() && d.left > 0 {
+		gp.Vel.Y = d.Speed
+		gp.buffered = 0
+		gp.jumping = true
+		d.left--
+	}
+}
+
+func (d *doubleJump) CanAirJump(gp *gopherPhys) bool {
+	return d.left > 0
+}
+
+func (d *doubleJump) Reset() {
+	d.left = d.Charges
+}
+
+func (d *doubleJump) Clone() Ability {
+	c := *d
+	return &c
+}
+
+func NewDoubleJump() *doubleJump {
+	d := &doubleJump{Charges: 1, Speed: 160}
+	// charged already, granted in the air it works before the gopher lands
+	d.left = d.Charges
+	return d
+}
+
+// airDash rushes horizontally in the air, ignoring gravity, once until the gopher lands or touches a wall
+type airDash struct {
+	Speed    float64
+	Duration float64
+	Cooldown float64
+
+	dashing  float64 // time left of the dash
+	cooldown float64
+	dir      float64
+	used     bool
+}
+
+func (d *airDash) Name() string { return "airDash" }
+
+func (d *airDash) BeforeMove(gp *gopherPhys, dt float64) {
+	d.cooldown -= dt
+	if controls.Dash && !gp.ground && !d.used && d.cooldown <= 0 {
+		d.dashing = d.Duration
+		d.cooldown = d.Cooldown
+		d.dir = gp.facing()
+		d.used = true
+	}
+	if d.dashing > 0 {
+		d.dashing -= dt
+		gp.Vel = pixel.V(d.dir*d.Speed, 0)
+		gp.jumping, gp.hang = false, false
+		if d.dashing <= 0 {
+			// come out of the dash at run speed
+			gp.Vel.X = d.dir * gp.move.RunSpeed
+		}
+	}
+}
+
+func (d *airDash) AfterMove(gp *gopherPhys, dt float64) {
+	if gp.ground || gp.wall != 0 {
+		d.used = false
+		// a wall stops the dash
+		if gp.wall == d.dir {
+			d.dashing = 0
+		}
+	}
+}
+
+func (d *airDash) Reset() {
+	d.dashing, d.cooldown, d.used = 0, 0, false
+}
+
+func (d *airDash) Clone() Ability {
+	c := *d
+	return &c
+}
+
+func NewAirDash() *airDash {
+	return &airDash{Speed: 200, Duration: 0.15, Cooldown: 0.3}
+}
+
+// groundPound dives straight down when down is pressed in the air, until the gopher lands
+type groundPound struct {
+	Speed float64
+	// OnImpact is called when the gopher lands from a ground pound
+	OnImpact func(pos pixel.Vec)
+
+	pounding bool
+}
+
+func (p *groundPound) Name() string { return "groundPound" }
+
+func (p *groundPound) BeforeMove(gp *gopherPhys, dt float64) {
+	if controls.Down && !gp.ground && !p.pounding {
+		p.pounding = true
+	}
+	if p.pounding {
+		gp.Vel = pixel.V(0, -p.Speed)
+		gp.jumping, gp.hang = false, false
+	}
+}
+
+func (p *groundPound) AfterMove(gp *gopherPhys, dt float64) {
+	// bouncing off a surface ends the pound too, else it would dive back onto it forever
+	if !p.pounding || !gp.ground && !gp.bounced {
+		return
+	}
+	p.pounding = false
+	if p.OnImpact != nil {
+		p.OnImpact(pixel.V(gp.Rect.Center().X, gp.Rect.Min.Y))
+	}
+}
+
+// Pounding reports whether the gopher is diving
+func (p *groundPound) Pounding() bool {
+	return p.pounding
+}
+
+func (p *groundPound) Reset() {
+	p.pounding = false
+}
+
+func (p *groundPound) Clone() Ability {
+	c := *p
+	c.OnImpact = nil
+	return &c
+}
+
+func NewGroundPound() *groundPound {
+	return &groundPound{Speed: 320}
+}
+
+// blaster shoots in the direction the gopher faces while fire is held
+type blaster struct {
+	Emitter *emitter
+}
+
+func (b *blaster) Name() string { return "blaster" }
+
+func (b *blaster) BeforeMove(gp *gopherPhys, dt float64) {}
+
+func (b *blaster) AfterMove(gp *gopherPhys, dt float64) {
+	b.Emitter.Update(dt)
+	if controls.Fire && !gp.simulated {
+		b.Emitter.Fire(Game.currentScene, gp.Rect.Center(), pixel.V(gp.facing(), 0))
+	}
+}
+
+func (b *blaster) Reset() {
+	b.Emitter.cooldown = 0
+}
+
+func (b *blaster) Clone() Ability {
+	em := *b.Emitter
+	return &blaster{Emitter: &em}
+}
+
+func NewBlaster() *blaster {
+	return &blaster{Emitter: NewEmitter(Projectile{
+		Speed:    240,
+		Lifetime: 0.6,
+		Radius:   1.5,
+		Color:    pixel.RGB(1, 0.9, 0.3),
+	}, 0.25)}
+}
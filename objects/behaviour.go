@@ -0,0 +1,238 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Behaviour steers an enemy, it is asked every step before the enemy moves.
+// It sets the direction the enemy walks to and may make it jump.
+type Behaviour interface {
+	Steer(e *enemy, dt float64)
+}
+
+// Behaviours create the behaviours by name, enemies take theirs from the "behaviour" property
+var Behaviours = map[string]func() Behaviour{
+	"patrol": func() Behaviour { return NewPatrol() },
+	"chase":  func() Behaviour { return NewChase(96) },
+	"pursue": func() Behaviour { return NewPursue() },
+	"shoot":  func() Behaviour { return NewShoot(128) },
+}
+
+// patrol walks back and forth, turning at walls and at edges instead of falling off,
+// or jumping the gaps it can jump
+type patrol struct {
+	JumpGaps bool
+}
+
+func (p *patrol) Steer(e *enemy, dt float64) {
+	if e.Dir == 0 {
+		e.Dir = e.facing
+	}
+	if !e.ground {
+		return
+	}
+	if e.wall == e.Dir {
+		e.Dir = -e.Dir
+		return
+	}
+	if e.EdgeAhead() {
+		if p.JumpGaps && e.CanJumpGap() {
+			e.Jump()
+			return
+		}
+		e.Dir = -e.Dir
+	}
+}
+
+// Configure reads "jumpGaps"
+func (p *patrol) Configure(props Properties) (err error) {
+	p.JumpGaps, err = props.Bool("jumpGaps", p.JumpGaps)
+	return err
+}
+
+func NewPatrol() *patrol {
+	return &patrol{}
+}
+
+// chase runs after the gopher while it is in sight within Range, else it patrols.
+// It keeps chasing for Memory seconds after losing sight of the gopher.
+type chase struct {
+	Range  float64
+	Memory float64
+	Patrol patrol
+
+	lost float64 // time since the gopher was last seen
+}
+
+func (c *chase) Steer(e *enemy, dt float64) {
+	target := e.Target()
+	if target == nil || target.Dead() {
+		c.Patrol.Steer(e, dt)
+		return
+	}
+	pos := target.Center()
+	if e.Sees(pos, c.Range) {
+		c.lost = 0
+	} else {
+		c.lost += dt
+	}
+	if c.lost > c.Memory {
+		c.Patrol.Steer(e, dt)
+		return
+	}
+
+	if !e.ground {
+		return
+	}
+	dx := pos.X - e.Rect.Center().X
+	if math.Abs(dx) < 2 {
+		// right under or above the gopher
+		e.Dir = 0
+		return
+	}
+	e.Dir = math.Copysign(1, dx)
+	if e.EdgeAhead() {
+		if c.Patrol.JumpGaps && e.CanJumpGap() {
+			e.Jump()
+			return
+		}
+		// wait at the edge
+		e.facing = e.Dir
+		e.Dir = 0
+	}
+}
+
+// Configure reads "range", "memory" and the properties of the patrol
+func (c *chase) Configure(props Properties) (err error) {
+	if c.Range, err = props.Float("range", c.Range); err != nil {
+		return err
+	}
+	if c.Memory, err = props.Float("memory", c.Memory); err != nil {
+		return err
+	}
+	return c.Patrol.Configure(props)
+}
+
+// NewChase creates a chase seeing rng far, jumping the gaps
+func NewChase(rng float64) *chase {
+	return &chase{Range: rng, Memory: 1, Patrol: patrol{JumpGaps: true}}
+}
+
+// pursue goes after the gopher across the platforms, following plans made on a navigation graph
+type pursue struct {
+	// Replan is the time between two plans, the gopher moves meanwhile
+	Replan float64
+
+	graph  *navGraph
+	plan   []NavStep
+	timer  float64
+	acting float64 // time since the action of the first step started, 0 while going to its start
+}
+
+// pursueGiveUp is how long an action may take before the enemy plans again, it may have missed a jump
+const pursueGiveUp = 2
+
+func (p *pursue) Steer(e *enemy, dt float64) {
+	target := e.Target()
+	if target == nil || target.Dead() {
+		e.Dir = 0
+		return
+	}
+	if !e.ground {
+		return
+	}
+	if p.graph == nil || p.graph.scene != e.scene {
+		p.graph = NewNavGraph(e.scene, e)
+	}
+
+	feet := pixel.V(e.Rect.Center().X, e.Rect.Min.Y)
+	node := p.graph.NodeAt(feet)
+	for len(p.plan) > 0 && p.plan[0].Node == node {
+		// step done
+		p.plan = p.plan[1:]
+		p.acting = 0
+	}
+	if p.acting > 0 {
+		p.acting += dt
+	}
+	p.timer -= dt
+	if p.acting == 0 && p.timer <= 0 || p.acting > pursueGiveUp {
+		p.timer = p.Replan
+		p.acting = 0
+		goal := target.Center()
+		goal.Y = target.Phys.Rect.Min.Y
+		p.plan, _ = p.graph.Path(feet, goal)
+	}
+
+	if len(p.plan) == 0 {
+		// on the surface of the gopher, or no way to it: go at it without falling off
+		dx := target.Center().X - feet.X
+		e.Dir = 0
+		if math.Abs(dx) >= 2 {
+			e.Dir = math.Copysign(1, dx)
+		}
+		if e.EdgeAhead() {
+			e.facing = e.Dir
+			e.Dir = 0
+		}
+		return
+	}
+
+	step := p.plan[0]
+	if p.acting == 0 {
+		if dx := step.From.X - feet.X; math.Abs(dx) > e.Speed*dt {
+			e.Dir = math.Copysign(1, dx)
+			return
+		}
+		p.acting = dt
+	}
+	e.Dir = 0
+	if dx := step.To.X - step.From.X; math.Abs(dx) >= navStep {
+		e.Dir = math.Copysign(1, dx)
+	}
+	if step.Action == NavJump {
+		e.Jump()
+	}
+}
+
+// Configure reads "replan"
+func (p *pursue) Configure(props Properties) (err error) {
+	p.Replan, err = props.Float("replan", p.Replan)
+	return err
+}
+
+func NewPursue() *pursue {
+	return &pursue{Replan: 0.5}
+}
+
+// shoot stands still and shoots at the gopher while it is in sight within Range, else it patrols
+type shoot struct {
+	Range  float64
+	Patrol patrol
+}
+
+func (sh *shoot) Steer(e *enemy, dt float64) {
+	target := e.Target()
+	if e.Weapon == nil || target == nil || target.Dead() || !e.Sees(target.Center(), sh.Range) {
+		sh.Patrol.Steer(e, dt)
+		return
+	}
+	e.Dir = 0
+	eye := e.Rect.Center()
+	e.Weapon.Fire(e.scene, eye, target.Center().Sub(eye))
+}
+
+// Configure reads "range" and the properties of the patrol
+func (sh *shoot) Configure(props Properties) (err error) {
+	if sh.Range, err = props.Float("range", sh.Range); err != nil {
+		return err
+	}
+	return sh.Patrol.Configure(props)
+}
+
+// NewShoot creates a shoot seeing rng far
+func NewShoot(rng float64) *shoot {
+	return &shoot{Range: rng}
+}
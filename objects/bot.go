@@ -0,0 +1,249 @@
+package objects
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/controls"
+)
+
+// BotStep is the time step of the bot simulation
+const BotStep = 1.0 / 60
+
+// botHold is how many steps the bot holds each input
+const botHold = 6
+
+// BotInput is an input held for a number of steps, jump is pressed on the first one and held on the others.
+// Hold keeps holding the jump of the previous inputs.
+type BotInput struct {
+	X      float64
+	Jump   bool
+	Hold   bool
+	Frames int
+}
+
+func (in BotInput) String() string {
+	var dir string
+	switch {
+	case in.X < 0:
+		dir = "L"
+	case in.X > 0:
+		dir = "R"
+	default:
+		dir = "-"
+	}
+	switch {
+	case in.Jump:
+		dir += "J"
+	case in.Hold:
+		dir += "H"
+	}
+	return fmt.Sprintf("%s%d", dir, in.Frames)
+}
+
+// BotInputs formats a sequence of inputs like "R12 RJ6 RH6 -6"
+func BotInputs(inputs []BotInput) string {
+	s := make([]string, len(inputs))
+	for i, in := range inputs {
+		s[i] = in.String()
+	}
+	return strings.Join(s, " ")
+}
+
+// BotError explains why the bot found no way to the goal
+type BotError struct {
+	Reason   string
+	Expanded int       // states the search went through
+	Closest  float64   // closest distance to a goal
+	BestPos  pixel.Vec // where the gopher got the closest
+	Best     []BotInput
+}
+
+func (e *BotError) Error() string {
+	return fmt.Sprintf("no solution: %s after %d states, closest to the goal was %.1f at %v with %s",
+		e.Reason, e.Expanded, e.Closest, e.BestPos, BotInputs(e.Best))
+}
+
+// botActions are the inputs the bot tries at each node
+var botActions = []BotInput{
+	{X: -1}, {X: 0}, {X: 1},
+	{X: -1, Jump: true}, {X: 0, Jump: true}, {X: 1, Jump: true},
+	{X: -1, Hold: true}, {X: 0, Hold: true}, {X: 1, Hold: true},
+}
+
+type botNode struct {
+	phys   gopherPhys
+	parent *botNode
+	input  BotInput
+	cost   float64 // time since the start
+	prio   float64
+	index  int
+}
+
+type botQueue []*botNode
+
+func (q botQueue) Len() int            { return len(q) }
+func (q botQueue) Less(i, j int) bool  { return q[i].prio < q[j].prio }
+func (q botQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i]; q[i].index = i; q[j].index = j }
+func (q *botQueue) Push(x interface{}) { n := x.(*botNode); n.index = len(*q); *q = append(*q, n) }
+func (q *botQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// botKey discretizes the state of the gopher, states with the same key are considered the same
+type botKey struct {
+	x, y, vx, vy    int
+	ground, jumping bool
+}
+
+func keyOf(gp *gopherPhys) botKey {
+	return botKey{
+		x:       int(math.Round(gp.Rect.Min.X / 2)),
+		y:       int(math.Round(gp.Rect.Min.Y / 2)),
+		vx:      int(math.Round(gp.Vel.X / 16)),
+		vy:      int(math.Round(gp.Vel.Y / 32)),
+		ground:  gp.ground,
+		jumping: gp.jumping,
+	}
+}
+
+// SimulateGopher runs the physics of a copy of the gopher with the inputs, in the current scene of the game,
+// and returns the copy. Only the gopher moves, the rest of the scene is not updated.
+func SimulateGopher(g *gopherAnim, inputs []BotInput) *gopherPhys {
+	phys := g.Phys.clone()
+	for _, in := range inputs {
+		simulate(&phys, in)
+	}
+	return &phys
+}
+
+func simulate(phys *gopherPhys, in BotInput) {
+	saved, savedHeld, savedDash, savedDown := controls.Controls, controls.JumpHeld, controls.Dash, controls.Down
+	controls.Dash, controls.Down = false, false
+	for f := 0; f < in.Frames; f++ {
+		controls.Controls = pixel.V(in.X, 0)
+		if in.Jump && f == 0 {
+			controls.Controls.Y = 1
+		}
+		controls.JumpHeld = in.Jump || in.Hold
+		phys.update(BotStep)
+	}
+	controls.Controls, controls.JumpHeld, controls.Dash, controls.Down = saved, savedHeld, savedDash, savedDown
+}
+
+// distTo is the distance from the rectangle to the closest goal
+func distTo(goals []*goal, r pixel.Rect) float64 {
+	best := math.Inf(1)
+	for _, g := range goals {
+		best = math.Min(best, math.Max(0, rectDist(r, pixel.Rect{Min: g.pos, Max: g.pos})-g.radius))
+	}
+	return best
+}
+
+// SolveLevel searches the inputs bringing the gopher from its position to a goal of the scene,
+// with a weighted A* over the inputs held for a few steps of the headless simulation.
+// The search stops after budget states and returns a *BotError explaining how far it got.
+// The scene is made the current scene of the game during the search, only the gopher moves.
+func SolveLevel(s *scene, g *gopherAnim, budget int) ([]BotInput, error) {
+	goals := FindObjects[*goal](s)
+	if len(goals) == 0 {
+		return nil, &BotError{Reason: "the scene has no goal"}
+	}
+
+	// the physics read the surfaces of the current scene
+	prev := Game.currentScene
+	Game.currentScene = s
+	defer func() { Game.currentScene = prev }()
+
+	// the gopher is lost once below every surface
+	floor := math.Inf(1)
+	for _, sf := range s.Surfaces() {
+		floor = math.Min(floor, sf.Rect.Min.Y)
+	}
+	floor -= 100
+
+	jp := g.Phys.jumpParams()
+	heuristic := func(gp *gopherPhys) float64 {
+		// time to run to the goal, weighted to search greedily
+		return 1.5 * distTo(goals, gp.Rect) / jp.runSpeed
+	}
+
+	start := &botNode{phys: g.Phys.clone()}
+	start.prio = heuristic(&start.phys)
+	queue := botQueue{start}
+	seen := map[botKey]bool{keyOf(&start.phys): true}
+
+	best := start
+	bestDist := distTo(goals, start.phys.Rect)
+	expanded := 0
+	for queue.Len() > 0 {
+		n := heap.Pop(&queue).(*botNode)
+		expanded++
+		if expanded > budget {
+			return nil, botFailure("budget exhausted", expanded-1, best, bestDist)
+		}
+
+		for _, in := range botActions {
+			if in.Jump && !n.phys.canJump() || in.Hold && !n.phys.jumping {
+				continue
+			}
+			in.Frames = botHold
+			child := &botNode{phys: n.phys.clone(), parent: n, input: in, cost: n.cost + botHold*BotStep}
+			simulate(&child.phys, in)
+
+			d := distTo(goals, child.phys.Rect)
+			if d < bestDist {
+				best, bestDist = child, d
+			}
+			if d == 0 {
+				return child.inputs(), nil
+			}
+			if child.phys.Rect.Max.Y < floor || child.phys.dead {
+				continue
+			}
+			k := keyOf(&child.phys)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			child.prio = child.cost + heuristic(&child.phys)
+			heap.Push(&queue, child)
+		}
+	}
+	return nil, botFailure("no more states to search", expanded, best, bestDist)
+}
+
+func botFailure(reason string, expanded int, best *botNode, dist float64) *BotError {
+	return &BotError{
+		Reason:   reason,
+		Expanded: expanded,
+		Closest:  dist,
+		BestPos:  best.phys.Rect.Center(),
+		Best:     best.inputs(),
+	}
+}
+
+// inputs returns the inputs leading to the node, the same consecutive inputs merged
+func (n *botNode) inputs() []BotInput {
+	var rev []BotInput
+	for ; n.parent != nil; n = n.parent {
+		rev = append(rev, n.input)
+	}
+	var inputs []BotInput
+	for i := len(rev) - 1; i >= 0; i-- {
+		in := rev[i]
+		last := len(inputs) - 1
+		if last >= 0 && !in.Jump && inputs[last].X == in.X && (inputs[last].Jump || inputs[last].Hold) == in.Hold {
+			inputs[last].Frames += in.Frames
+			continue
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs
+}
@@ -0,0 +1,60 @@
+package objects_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/loader"
+	"github.com/unknownTravelers/3D-jump-infinite/objects"
+)
+
+// botBudget is the states the bot may search, solving level.json takes about half of them
+const botBudget = 100000
+
+func TestSolveLevel(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the search takes a few seconds")
+	}
+	scene := objects.NewScene()
+	lvl, err := loader.Level("../level.json", scene)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goph, err := objects.NewGopher(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goph.Teleport(lvl.Spawn)
+	inputs, err := objects.SolveLevel(scene, goph, botBudget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Error("no inputs to reach the goal")
+	}
+}
+
+// the ledge is too high for a jump, the bot must jump again in the air
+func TestSolveDoubleJump(t *testing.T) {
+	for _, double := range []bool{false, true} {
+		scene := objects.NewScene()
+		scene.AddObjects(
+			objects.NewPlatform(pixel.R(-64, -8, 64, 0)),
+			objects.NewPlatform(pixel.R(16, 56, 64, 64)),
+			objects.NewGoal(pixel.V(40, 72), 4, 0),
+		)
+		goph, err := objects.NewGopher(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if double {
+			goph.Grant(objects.NewDoubleJump())
+		}
+		goph.Teleport(pixel.V(-32, 7))
+
+		_, err = objects.SolveLevel(scene, goph, 20000)
+		if solved := err == nil; solved != double {
+			t.Errorf("double jump %v: solved %v, want %v (%v)", double, solved, double, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+type collectibleKind int
+
+const (
+	coinKind collectibleKind = iota
+	gemKind
+)
+
+// collectible is picked up by the gopher touching it, adding Value to the score
+type collectible struct {
+	Pos   pixel.Vec
+	Value int
+	Color pixel.RGBA
+
+	kind  collectibleKind
+	scene *scene
+	t     float64
+}
+
+func (c *collectible) Init(s *scene) {
+	c.scene = s
+}
+
+func (c *collectible) Touch(gp *gopherPhys) {
+	if gp.simulated {
+		return
+	}
+	Game.collect(c)
+	c.scene.RemoveObjects(c)
+}
+
+func (c *collectible) Update(dt float64) {
+	c.t += dt
+}
+
+func (c *collectible) Bounds() pixel.Rect {
+	return pixel.R(c.Pos.X-4, c.Pos.Y-4, c.Pos.X+4, c.Pos.Y+4)
+}
+
+func (c *collectible) DrawLayer() (Layer, int) {
+	return LayerActors, 0
+}
+
+// Draw draws a spinning coin or a bobbing gem
+func (c *collectible) Draw(imd *imdraw.IMDraw) {
+	imd.Color = c.Color
+	switch c.kind {
+	case gemKind:
+		p := c.Pos.Add(pixel.V(0, math.Sin(c.t*3)))
+		imd.Push(p.Add(pixel.V(0, 4)), p.Add(pixel.V(3, 0)), p.Add(pixel.V(0, -4)), p.Add(pixel.V(-3, 0)))
+		imd.Polygon(0)
+	default:
+		imd.Push(c.Pos)
+		imd.Ellipse(pixel.V(math.Max(0.5, 3*math.Abs(math.Cos(c.t*4))), 3), 0)
+	}
+}
+
+func (c *collectible) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+// Configure reads "value"
+func (c *collectible) Configure(p Properties) (err error) {
+	value, err := p.Float("value", float64(c.Value))
+	if err != nil {
+		return err
+	}
+	if value < 0 || value != math.Trunc(value) {
+		return errors.Errorf("value must be a whole number not below 0, got %v", value)
+	}
+	c.Value = int(value)
+	return nil
+}
+
+func NewCoin(pos pixel.Vec) *collectible {
+	return &collectible{Pos: pos, Value: 1, Color: pixel.RGB(1, 0.85, 0.2), kind: coinKind}
+}
+
+func NewGem(pos pixel.Vec) *collectible {
+	return &collectible{Pos: pos, Value: 10, Color: pixel.RGB(0.3, 0.8, 1), kind: gemKind}
+}
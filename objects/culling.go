@@ -0,0 +1,71 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Bounded objects report their world bounds, the scene uses them to skip drawing
+// objects outside the view and updating objects far away from it.
+// Objects not implementing it are always drawn and updated.
+type Bounded interface {
+	Bounds() pixel.Rect
+}
+
+// CullStats counts the objects handled during the last update and draw of a scene
+type CullStats struct {
+	Updated int // objects updated
+	Asleep  int // objects not updated because they are outside the activity radius
+	Drawn   int // objects drawn
+	Culled  int // objects not drawn because they are outside the view
+}
+
+// rectDist returns the distance between the closest points of two rectangles, 0 if they overlap
+func rectDist(a, b pixel.Rect) float64 {
+	dx := math.Max(0, math.Max(a.Min.X-b.Max.X, b.Min.X-a.Max.X))
+	dy := math.Max(0, math.Max(a.Min.Y-b.Max.Y, b.Min.Y-a.Max.Y))
+	return math.Hypot(dx, dy)
+}
+
+// SetView sets the visible world rectangle, objects outside of it are not drawn
+func (s *scene) SetView(r pixel.Rect) {
+	s.view = r.Norm()
+	s.hasView = true
+}
+
+// ClearView disables the culling, every object is drawn and updated
+func (s *scene) ClearView() {
+	s.hasView = false
+}
+
+func (s *scene) View() (pixel.Rect, bool) {
+	return s.view, s.hasView
+}
+
+// SetActivityRadius stops updating the objects further than r from the view, 0 disables it
+func (s *scene) SetActivityRadius(r float64) {
+	s.activityRadius = r
+}
+
+func (s *scene) Stats() CullStats {
+	return s.stats
+}
+
+// visible reports whether the object should be drawn
+func (s *scene) visible(o Object) bool {
+	b, ok := o.(Bounded)
+	if !ok || !s.hasView {
+		return true
+	}
+	return rectDist(b.Bounds().Norm(), s.view) == 0
+}
+
+// active reports whether the object should be updated
+func (s *scene) active(o Object) bool {
+	b, ok := o.(Bounded)
+	if !ok || !s.hasView || s.activityRadius <= 0 {
+		return true
+	}
+	return rectDist(b.Bounds().Norm(), s.view) <= s.activityRadius
+}
@@ -0,0 +1,375 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+const (
+	// stompWindow is how long ago the feet of a falling gopher may have been above an enemy to stomp it,
+	// so fast falls don't go through the top of the enemy between two steps
+	stompWindow = 1.0 / 30
+	// stompBounce is the speed the gopher bounces up at after a stomp, holding jump keeps all of it
+	stompBounce = 160
+	// enemyDeathTime is how long a stomped enemy stays flattened
+	enemyDeathTime = 0.5
+)
+
+type enemyKind int
+
+const (
+	walkerKind enemyKind = iota
+	chaserKind
+	shooterKind
+)
+
+// enemy walks on the surfaces where its Behaviour steers it.
+// The gopher landing on top of it kills it, touching it otherwise kills the gopher.
+type enemy struct {
+	Rect      pixel.Rect
+	Vel       pixel.Vec
+	Speed     float64
+	JumpSpeed float64 // 0 for enemies that can't jump
+	Gravity   float64
+	Color     pixel.RGBA
+	Behaviour Behaviour
+	// Dir is the direction the enemy wants to walk to, -1, 0 or +1, set by the behaviour
+	Dir float64
+	// Weapon is nil for enemies that don't shoot
+	Weapon *emitter
+
+	kind   enemyKind
+	scene  *scene
+	target *gopherAnim
+	facing float64
+	ground bool
+	floor  Surface // the surface stood on, when on the ground
+	wall   float64 // side blocked during the last step
+	dead   bool
+	dying  float64 // time since the enemy was stomped
+	t      float64
+}
+
+func (e *enemy) Init(s *scene) {
+	e.scene = s
+}
+
+// Target returns the gopher the enemy is after, nil if the scene has none
+func (e *enemy) Target() *gopherAnim {
+	if e.target == nil || !e.scene.Contains(e.target) {
+		e.target = nil
+		if gophers := FindObjects[*gopherAnim](e.scene); len(gophers) > 0 {
+			e.target = gophers[0]
+		}
+	}
+	return e.target
+}
+
+// Sees reports whether pos is within rng in front of the enemy, with no surface in the way
+func (e *enemy) Sees(pos pixel.Vec, rng float64) bool {
+	eye := e.Rect.Center()
+	if eye.To(pos).Len() > rng || (pos.X-eye.X)*e.facing < 0 {
+		return false
+	}
+	_, blocked := e.scene.Raycast(eye, pos)
+	return !blocked
+}
+
+// EdgeAhead reports whether the enemy walking to Dir would step off its ground
+func (e *enemy) EdgeAhead() bool {
+	if !e.ground || e.Dir == 0 {
+		return false
+	}
+	x := e.Rect.Max.X + 1
+	if e.Dir < 0 {
+		x = e.Rect.Min.X - 1
+	}
+	for _, s := range e.scene.Surfaces() {
+		if s.Rect.Min.X <= x && x <= s.Rect.Max.X && math.Abs(s.Rect.Max.Y-e.Rect.Min.Y) <= 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// CanJumpGap reports whether the enemy at an edge can jump to a surface ahead of it in Dir
+func (e *enemy) CanJumpGap() bool {
+	if !e.ground || e.JumpSpeed <= 0 || e.Dir == 0 {
+		return false
+	}
+	jp := e.jumpParams()
+	for _, s := range e.scene.Surfaces() {
+		gap := s.Rect.Min.X - e.Rect.Max.X
+		if e.Dir < 0 {
+			gap = e.Rect.Min.X - s.Rect.Max.X
+		}
+		if gap <= 0 || s.Rect.W() < e.Rect.W() {
+			continue
+		}
+		// land with the whole hitbox on the surface
+		if r, ok := jp.reach(s.Rect.Max.Y - e.Rect.Min.Y); ok && gap+e.Rect.W() <= r {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *enemy) jumpParams() jumpParams {
+	return jumpParams{
+		gravity:   e.Gravity,
+		runSpeed:  e.Speed,
+		jumpSpeed: e.JumpSpeed,
+		size:      e.Rect.Size(),
+	}
+}
+
+// Jump jumps towards Dir if the enemy is on the ground
+func (e *enemy) Jump() {
+	if !e.ground || e.JumpSpeed <= 0 {
+		return
+	}
+	e.Vel = pixel.V(e.Dir*e.Speed, e.JumpSpeed)
+	e.ground = false
+}
+
+// Kill flattens the enemy, it is removed from the scene shortly after
+func (e *enemy) Kill() {
+	e.dead = true
+	e.dying = 0
+}
+
+func (e *enemy) Dead() bool {
+	return e.dead
+}
+
+func (e *enemy) Update(dt float64) {
+	e.t += dt
+	if e.dead {
+		e.dying += dt
+		if e.dying >= enemyDeathTime {
+			e.scene.RemoveObjects(e)
+		}
+		return
+	}
+
+	if e.Weapon != nil {
+		e.Weapon.Update(dt)
+	}
+	if e.Behaviour != nil {
+		e.Behaviour.Steer(e, dt)
+	}
+	if e.Dir != 0 {
+		e.facing = e.Dir
+	}
+	// no steering in the air
+	if e.ground {
+		e.Vel.X = e.Dir * e.Speed
+	}
+	e.Vel.Y += e.Gravity * dt
+
+	surfaces := e.scene.Surfaces()
+	feet := e.Rect.Min.Y
+
+	// moving ground already moved, move along with it
+	if e.ground {
+		e.Rect = e.Rect.Moved(e.floor.Vel.Scaled(dt))
+	}
+
+	e.Rect = e.Rect.Moved(pixel.V(e.Vel.X*dt, 0))
+	e.wall = 0
+	for _, s := range surfaces {
+		if s.OneWay || !overlaps(e.Rect, s.Rect) {
+			continue
+		}
+		if e.Vel.X > 0 {
+			e.Rect = e.Rect.Moved(pixel.V(s.Rect.Min.X-e.Rect.Max.X, 0))
+			e.wall = +1
+		} else if e.Vel.X < 0 {
+			e.Rect = e.Rect.Moved(pixel.V(s.Rect.Max.X-e.Rect.Min.X, 0))
+			e.wall = -1
+		}
+		e.Vel.X = 0
+	}
+
+	e.Rect = e.Rect.Moved(pixel.V(0, e.Vel.Y*dt))
+	e.ground = false
+	for _, s := range surfaces {
+		if e.Rect.Max.X-s.Rect.Min.X <= overlapEpsilon || s.Rect.Max.X-e.Rect.Min.X <= overlapEpsilon {
+			continue
+		}
+		switch {
+		case e.Vel.Y <= math.Max(0, s.Vel.Y) && e.Rect.Min.Y <= s.Rect.Max.Y && feet >= s.Rect.Max.Y-s.Vel.Y*dt-overlapEpsilon:
+			// land like the gopher does
+			e.Rect = e.Rect.Moved(pixel.V(0, s.Rect.Max.Y-e.Rect.Min.Y))
+			e.Vel.Y = 0
+			e.ground = true
+			e.floor = s
+		case !s.OneWay && e.Vel.Y > 0 && overlaps(e.Rect, s.Rect):
+			e.Rect = e.Rect.Moved(pixel.V(0, s.Rect.Min.Y-e.Rect.Max.Y))
+			e.Vel.Y = 0
+		}
+	}
+}
+
+// Touch kills the enemy if the gopher falls on it, else the gopher
+func (e *enemy) Touch(gp *gopherPhys) {
+	if e.dead || gp.dead {
+		return
+	}
+	if gp.Vel.Y < 0 && gp.Rect.Min.Y-gp.Vel.Y*stompWindow >= e.Rect.Max.Y {
+		// bounce off the top of the enemy
+		gp.moveY(e.Rect.Max.Y)
+		gp.Vel.Y = stompBounce
+		gp.ground = false
+		gp.coyote = 0
+		gp.jumping = true
+		if !gp.simulated {
+			e.Kill()
+		}
+		return
+	}
+	gp.Kill()
+}
+
+func (e *enemy) Bounds() pixel.Rect {
+	return e.Rect
+}
+
+func (e *enemy) DrawLayer() (Layer, int) {
+	return LayerActors, 0
+}
+
+// Draw draws the body with an eye on the side the enemy faces, flattened once stomped
+func (e *enemy) Draw(imd *imdraw.IMDraw) {
+	r := e.Rect
+	if e.dead {
+		imd.Color = e.Color.Scaled(1 - e.dying/enemyDeathTime)
+		imd.Push(r.Min, pixel.V(r.Max.X, r.Min.Y+2))
+		imd.Rectangle(0)
+		return
+	}
+
+	// waddle while walking
+	if e.ground && e.Vel.X != 0 {
+		r = r.Moved(pixel.V(0, math.Abs(math.Sin(e.t*12))))
+	}
+	imd.Color = e.Color
+	switch e.kind {
+	case shooterKind:
+		imd.Push(r.Min, r.Max)
+		imd.Rectangle(0)
+		// the barrel
+		c := r.Center()
+		imd.Push(c, c.Add(pixel.V(e.facing*(r.W()/2+3), 0)))
+		imd.Line(2)
+	case chaserKind:
+		imd.Push(r.Min, r.Max)
+		imd.Rectangle(0)
+		// ears
+		for _, x := range []float64{r.Min.X + 2, r.Max.X - 2} {
+			imd.Push(pixel.V(x-2, r.Max.Y), pixel.V(x, r.Max.Y+3), pixel.V(x+2, r.Max.Y))
+			imd.Polygon(0)
+		}
+	default:
+		// a shell, round on top
+		imd.Push(r.Min, pixel.V(r.Max.X, r.Min.Y+r.H()/2))
+		imd.Rectangle(0)
+		imd.Push(pixel.V(r.Center().X, r.Min.Y+r.H()/2))
+		imd.CircleArc(r.W()/2, 0, math.Pi, 0)
+	}
+
+	eye := pixel.V(r.Center().X+e.facing*r.W()/4, r.Min.Y+r.H()*0.6)
+	imd.Color = pixel.RGB(1, 1, 1)
+	imd.Push(eye)
+	imd.Circle(1.5, 0)
+}
+
+func (e *enemy) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+// Configure reads "speed", "jump", the jump speed, "dir", the direction it starts walking to,
+// "behaviour", one of Behaviours, "interval", the time between shots of its weapon, and the properties of its behaviour
+func (e *enemy) Configure(p Properties) (err error) {
+	name, err := p.String("behaviour", "")
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		newBehaviour, ok := Behaviours[name]
+		if !ok {
+			return errors.Errorf("unknown behaviour %q", name)
+		}
+		e.Behaviour = newBehaviour()
+	}
+	if e.Speed, err = p.Float("speed", e.Speed); err != nil {
+		return err
+	}
+	if e.JumpSpeed, err = p.Float("jump", e.JumpSpeed); err != nil {
+		return err
+	}
+	if e.Dir, err = p.Float("dir", e.Dir); err != nil {
+		return err
+	}
+	if e.Speed < 0 || e.JumpSpeed < 0 {
+		return errors.Errorf("speed and jump must not be negative, got %v and %v", e.Speed, e.JumpSpeed)
+	}
+	if e.Dir != -1 && e.Dir != 1 {
+		return errors.Errorf("dir must be -1 or 1, got %v", e.Dir)
+	}
+	e.facing = e.Dir
+	if e.Weapon != nil {
+		if e.Weapon.Interval, err = p.Float("interval", e.Weapon.Interval); err != nil {
+			return err
+		}
+	}
+	if c, ok := e.Behaviour.(Configurable); ok {
+		return c.Configure(p)
+	}
+	return nil
+}
+
+func newEnemy(kind enemyKind, pos pixel.Vec, size pixel.Vec, speed float64, b Behaviour) *enemy {
+	return &enemy{
+		Rect:      pixel.R(pos.X-size.X/2, pos.Y, pos.X+size.X/2, pos.Y+size.Y),
+		Speed:     speed,
+		Gravity:   DefaultMovement().Gravity,
+		Behaviour: b,
+		Dir:       +1,
+		kind:      kind,
+		facing:    +1,
+	}
+}
+
+// NewWalker creates a slow enemy standing on pos, walking back and forth on its platform
+func NewWalker(pos pixel.Vec) *enemy {
+	e := newEnemy(walkerKind, pos, pixel.V(10, 8), 24, NewPatrol())
+	e.Color = pixel.RGB(0.8, 0.3, 0.2)
+	return e
+}
+
+// NewChaser creates an enemy standing on pos, running after the gopher once it sees it and jumping over gaps
+func NewChaser(pos pixel.Vec) *enemy {
+	e := newEnemy(chaserKind, pos, pixel.V(10, 10), 56, NewChase(96))
+	e.JumpSpeed = 200
+	e.Color = pixel.RGB(0.6, 0.3, 0.8)
+	return e
+}
+
+// NewShooter creates an enemy standing on pos, stopping to shoot at the gopher once it sees it
+func NewShooter(pos pixel.Vec) *enemy {
+	e := newEnemy(shooterKind, pos, pixel.V(10, 10), 20, NewShoot(128))
+	e.Color = pixel.RGB(0.3, 0.6, 0.3)
+	e.Weapon = NewEmitter(Projectile{
+		Speed:    120,
+		Lifetime: 1.5,
+		Radius:   2,
+		Color:    pixel.RGB(1, 0.4, 0.2),
+		Hostile:  true,
+	}, 1.5)
+	return e
+}
@@ -3,6 +3,14 @@ package objects
 type game struct {
 	scenes       []*scene
 	currentScene *scene
+
+	// Score adds up the value of every collectible picked up
+	Score int
+	// OnComplete is called with the stats of the level when the gopher reaches the goal
+	OnComplete func(LevelStats)
+	level      LevelStats
+	// picked are the collectibles picked up since the level started
+	picked []*collectible
 }
 
 var Game *game
@@ -21,6 +29,20 @@ func (g *game) GetCurrentScene() *scene {
 func (g *game) AddScenes(s ...*scene) {
 	g.scenes = append(g.scenes, s...)
 	if g.currentScene == nil {
-		g.currentScene = s[0]
+		g.SetCurrentScene(s[0])
+	}
+}
+
+// SetCurrentScene exits the current scene and enters s
+func (g *game) SetCurrentScene(s *scene) {
+	if g.currentScene == s {
+		return
+	}
+	if g.currentScene != nil {
+		g.currentScene.exit()
+	}
+	g.currentScene = s
+	if s != nil {
+		s.enter()
 	}
 }
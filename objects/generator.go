@@ -0,0 +1,150 @@
+package objects
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// Difficulty describes the platforms made by the generator, the gaps and heights are
+// fractions (0 to 1) of what the gopher can jump.
+type Difficulty struct {
+	GapMin, GapMax     float64
+	RiseMax, DropMax   float64
+	WidthMin, WidthMax float64 // in world units
+}
+
+func (d Difficulty) lerp(to Difficulty, t float64) Difficulty {
+	l := func(a, b float64) float64 { return a + (b-a)*t }
+	return Difficulty{
+		GapMin:   l(d.GapMin, to.GapMin),
+		GapMax:   l(d.GapMax, to.GapMax),
+		RiseMax:  l(d.RiseMax, to.RiseMax),
+		DropMax:  l(d.DropMax, to.DropMax),
+		WidthMin: l(d.WidthMin, to.WidthMin),
+		WidthMax: l(d.WidthMax, to.WidthMax),
+	}
+}
+
+// generator streams platforms ahead of the gopher and removes the ones far behind it.
+// Every gap is checked against the jump the gopher can make with its physics.
+type generator struct {
+	Ahead  float64 // how far ahead of the gopher platforms exist
+	Behind float64 // how far behind the gopher platforms are removed
+
+	// the difficulty goes from Easy to Hard over RampDistance
+	Easy, Hard   Difficulty
+	RampDistance float64
+
+	rng       *rand.Rand
+	target    *gopherAnim
+	scene     *scene
+	start     float64
+	last      pixel.Rect // the last generated platform
+	platforms []*platform
+}
+
+func (g *generator) Init(s *scene) {
+	g.scene = s
+
+	// the first platform is under the gopher
+	feet := g.target.Phys.Rect
+	first := NewPlatform(pixel.R(feet.Min.X-40, feet.Min.Y-2, feet.Max.X+40, feet.Min.Y))
+	g.start = first.Rect.Max.X
+	g.add(first)
+}
+
+func (g *generator) add(p *platform) {
+	g.last = p.Rect
+	g.platforms = append(g.platforms, p)
+	g.scene.AddObjects(p)
+}
+
+// next creates the platform following the last one
+func (g *generator) next() *platform {
+	jp := g.target.Phys.jumpParams()
+	d := g.Easy.lerp(g.Hard, math.Min(1, (g.last.Max.X-g.start)/g.RampDistance))
+	between := func(min, max float64) float64 { return min + g.rng.Float64()*(max-min) }
+
+	// rise up to RiseMax of the apex, or drop up to DropMax of a screen
+	var dh float64
+	if g.rng.Intn(2) == 0 {
+		dh = between(0, d.RiseMax) * jp.apex() * jumpMargin
+	} else {
+		dh = -between(0, d.DropMax) * 120
+	}
+
+	maxGap, ok := jp.maxGap(dh)
+	if !ok {
+		dh, maxGap = 0, jp.size.X
+	}
+	gap := between(d.GapMin, d.GapMax) * maxGap
+	width := between(d.WidthMin, d.WidthMax)
+
+	min := pixel.V(g.last.Max.X+gap, g.last.Max.Y+dh-2)
+	p := NewPlatform(pixel.Rect{Min: min, Max: min.Add(pixel.V(width, 2))})
+
+	// never happens with a gap under maxGap, but the levels must stay beatable
+	if !jp.canJump(Surface{Rect: g.last, OneWay: true}, Surface{Rect: p.Rect, OneWay: true}) {
+		p.Rect = p.Rect.Moved(pixel.V(g.last.Max.X-p.Rect.Min.X, 0))
+	}
+	return p
+}
+
+func (g *generator) Update(dt float64) {
+	// the gopher comes back on the last platform it stood on, after falling far below the platforms
+	if g.target.Phys.ground && !g.target.Dead() {
+		g.target.SetSpawn(g.target.Center())
+	}
+	low := g.last.Min.Y
+	for _, p := range g.platforms {
+		low = math.Min(low, p.Rect.Min.Y)
+	}
+	if g.target.Phys.Rect.Max.Y < low-200 {
+		g.target.Kill()
+	}
+
+	x := g.target.Phys.Rect.Center().X
+	for g.last.Max.X < x+g.Ahead {
+		g.add(g.next())
+	}
+
+	// free the platforms far behind, they are in order
+	n := 0
+	for n < len(g.platforms)-1 && g.platforms[n].Rect.Max.X < x-g.Behind {
+		g.scene.RemoveObjects(g.platforms[n])
+		g.platforms[n] = nil
+		n++
+	}
+	g.platforms = g.platforms[n:]
+}
+
+func (g *generator) Draw(imd *imdraw.IMDraw) {}
+
+func (g *generator) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+// NewGenerator creates a generator making platforms for the target, add it to the scene after the target
+func NewGenerator(seed int64, target *gopherAnim) *generator {
+	return &generator{
+		Ahead:  320,
+		Behind: 320,
+		Easy: Difficulty{
+			GapMin: 0.1, GapMax: 0.4,
+			RiseMax: 0.3, DropMax: 0.1,
+			WidthMin: 40, WidthMax: 80,
+		},
+		Hard: Difficulty{
+			GapMin: 0.6, GapMax: 1,
+			RiseMax: 1, DropMax: 0.5,
+			WidthMin: 10, WidthMax: 24,
+		},
+		RampDistance: 10000,
+		rng:          rand.New(rand.NewSource(seed)),
+		target:       target,
+	}
+}
@@ -26,6 +26,31 @@ func (g *goal) Update(dt float64) {
 	}
 }
 
+// Touch completes the level
+func (g *goal) Touch(gp *gopherPhys) {
+	if !gp.simulated {
+		Game.CompleteLevel()
+	}
+}
+
+func (g *goal) Init(s *scene) {
+	for i := range g.cols {
+		g.cols[i] = RandomNiceColor()
+	}
+}
+
+func (g *goal) EnterScene(s *scene) {
+	g.counter = 0
+}
+
+func (g *goal) Bounds() pixel.Rect {
+	return pixel.R(g.pos.X-g.radius, g.pos.Y-g.radius, g.pos.X+g.radius, g.pos.Y+g.radius)
+}
+
+func (g *goal) DrawLayer() (Layer, int) {
+	return LayerActors, 0
+}
+
 func (g *goal) Draw(imd *imdraw.IMDraw) {
 	for i := len(g.cols) - 1; i >= 0; i-- {
 		imd.Color = g.cols[i]
@@ -34,10 +59,18 @@ func (g *goal) Draw(imd *imdraw.IMDraw) {
 	}
 }
 
-func (g *goal) Collide(r pixel.Rect) *colliders.CollisionInfo {
+func (g *goal) Collide(col colliders.Collider) *colliders.CollisionInfo {
 	return nil
 }
 
+func (g *goal) Configure(p Properties) (err error) {
+	if g.radius, err = p.Float("radius", g.radius); err != nil {
+		return err
+	}
+	g.step, err = p.Float("step", g.step)
+	return err
+}
+
 func NewGoal(pos pixel.Vec, rad, step float64) *goal {
 	return &goal{
 		pos:    pos,
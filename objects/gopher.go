@@ -5,6 +5,7 @@ import (
 
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
 	"github.com/unknownTravelers/3D-jump-infinite/colliders"
 	"github.com/unknownTravelers/3D-jump-infinite/controls"
 )
@@ -15,6 +16,17 @@ const (
 	idle animState = iota
 	running
 	jumping
+	wallSliding
+	wallJumping
+)
+
+// wallReach is how far a wall can be from the side of the gopher and still be touched
+const wallReach = 0.5
+
+// the dead gopher hops up and falls off the screen for deathTime before coming back
+const (
+	deathHop  = 160
+	deathTime = 1.2
 )
 
 type gopherAnim struct {
@@ -30,75 +42,354 @@ type gopherAnim struct {
 
 	sprite *pixel.Sprite
 	Phys   *gopherPhys
+
+	dying float64 // time since the gopher died
+	// OnDeath is called when the gopher dies, OnRespawn when it comes back
+	OnDeath   func()
+	OnRespawn func()
 }
 
 type gopherPhys struct {
-	gravity   float64
-	runSpeed  float64
-	jumpSpeed float64
+	move Movement
 
 	Rect   pixel.Rect
 	Vel    pixel.Vec
 	ground bool
+	// bounced is true on the step the gopher bounced off the top of a surface instead of landing
+	bounced bool
+	// material and owner of the ground the gopher stands on
+	material *Material
+	floor    Object
+
+	coyote   float64 // time left to jump after leaving the ground
+	buffered float64 // time left to jump after jump was pressed
+	jumping  bool    // rising from a jump with jump held
+	hang     bool    // past the top of a jump with jump still held, hanging while slow
+
+	wall     float64 // -1 or +1 when touching a wall on the left or on the right
+	lockout  float64 // time left before the controls steer the gopher again
+	wallJump bool    // flying from a wall jump, until landing or touching a wall
+
+	abilities []Ability
+	simulated bool
+
+	// OnLand is called when the gopher lands, with its falling speed
+	OnLand func(speed float64)
+
+	// Spawn is where the center of the gopher comes back after dying, checkpoints move it
+	Spawn pixel.Vec
+	dead  bool
+}
+
+// Kill kills the gopher, it stops moving until it respawns
+func (gp *gopherPhys) Kill() {
+	gp.dead = true
 }
 
 func (gp *gopherPhys) update(dt float64) {
+	if gp.dead {
+		return
+	}
+
 	// apply controls
-	switch {
-	case controls.Controls.X < 0:
-		gp.Vel.X = -gp.runSpeed
-	case controls.Controls.X > 0:
-		gp.Vel.X = +gp.runSpeed
-	default:
-		gp.Vel.X = 0
+	gp.run(dt)
+
+	// remember the jump for a moment, it may be pressed just before landing
+	if controls.Controls.Y > 0 {
+		gp.buffered = gp.move.JumpBuffer
 	}
 
-	// apply gravity and velocity
-	gp.Vel.Y += gp.gravity * dt
-	gp.Rect = gp.Rect.Moved(gp.Vel.Scaled(dt))
+	// apply gravity, hanging a bit at the apex of a held jump, on the way up and down
+	gravity := gp.move.Gravity
+	if (gp.jumping || gp.hang) && math.Abs(gp.Vel.Y) < gp.move.ApexSpeed {
+		gravity *= gp.move.ApexHang
+	}
+	gp.Vel.Y += gravity * dt
+
+	// slide down slowly while pushing against a wall
+	if gp.sliding() && gp.Vel.Y < -gp.move.WallSlideSpeed {
+		gp.Vel.Y = -gp.move.WallSlideSpeed
+	}
+
+	for _, a := range gp.abilities {
+		a.BeforeMove(gp, dt)
+	}
+
+	// move one axis at a time, checking collisions against each surface
+	surfaces := Game.currentScene.Surfaces()
+	wasGround := gp.ground
+	fallSpeed := -gp.Vel.Y
+	feet := gp.Rect.Min.Y
+
+	// the moving ground already moved, move along with it, both moves collide with the surfaces
+	carry := gp.carried(surfaces)
+
+	dx := (gp.Vel.X + carry.X) * dt
+	gp.Rect = gp.Rect.Moved(pixel.V(dx, 0))
+	pushed := false
+	for _, s := range surfaces {
+		if s.OneWay || !overlaps(gp.Rect, s.Rect) {
+			continue
+		}
+		// push the gopher back out of the side it came from, or the closest side if a surface moved into it
+		left := dx > 0 || dx == 0 && gp.Rect.Center().X < s.Rect.Center().X
+		pushed = pushed || dx == 0
+		if left {
+			gp.moveX(s.Rect.Min.X - gp.Rect.W())
+		} else {
+			gp.moveX(s.Rect.Max.X)
+		}
+		if left == (gp.Vel.X > 0) && gp.Vel.X != 0 {
+			gp.Vel.X = bounce(gp.Vel.X, s.Bounce)
+		}
+	}
 
-	// check collisions against each platform
+	dy := (gp.Vel.Y + carry.Y) * dt
+	gp.Rect = gp.Rect.Moved(pixel.V(0, dy))
 	gp.ground = false
-	if gp.Vel.Y <= 0 {
-		for _, o := range Game.currentScene.objects {
-			p, ok := o.(*platform) // TODO: needs to be generalized to object (Collide)
-			if !ok {
-				continue
-			}
-			if gp.Rect.Max.X <= p.Rect.Min.X || gp.Rect.Min.X >= p.Rect.Max.X {
-				continue
-			}
-			if gp.Rect.Min.Y > p.Rect.Max.Y || gp.Rect.Min.Y < p.Rect.Max.Y+gp.Vel.Y*dt {
-				continue
+	gp.bounced = false
+	for _, s := range surfaces {
+		if gp.Rect.Max.X-s.Rect.Min.X <= overlapEpsilon || s.Rect.Max.X-gp.Rect.Min.X <= overlapEpsilon {
+			continue
+		}
+		switch {
+		case gp.Vel.Y <= math.Max(0, s.Vel.Y) && gp.Rect.Min.Y <= s.Rect.Max.Y && feet >= s.Rect.Max.Y-s.Vel.Y*dt-overlapEpsilon:
+			// the feet crossed the top of the surface during this frame, land on it.
+			// Moving surfaces moved before the gopher, the feet were above where the top was.
+			gp.moveY(s.Rect.Max.Y)
+			if up := bounce(gp.Vel.Y-s.Vel.Y, s.Bounce); up > 0 {
+				gp.Vel.Y = s.Vel.Y + up
+				gp.jumping, gp.hang = false, false
+				gp.bounced = true
+				break
 			}
 			gp.Vel.Y = 0
-			gp.Rect = gp.Rect.Moved(pixel.V(0, p.Rect.Max.Y-gp.Rect.Min.Y))
 			gp.ground = true
+			gp.material = s.material()
+			gp.floor = s.Owner
+		case !s.OneWay && dy > 0 && overlaps(gp.Rect, s.Rect):
+			// bump the head, or stop being lifted
+			if gp.Vel.Y > 0 {
+				gp.Vel.Y = bounce(gp.Vel.Y, s.Bounce)
+			}
+			gp.moveY(s.Rect.Min.Y - gp.Rect.H())
+		}
+	}
+
+	// moved or pushed by a surface into another one, the gopher is crushed between them.
+	// The ground carrying it counts even if one way, else a ceiling would push the gopher through it.
+	if carry != pixel.ZV || pushed {
+		for _, s := range surfaces {
+			if (!s.OneWay || carry != pixel.ZV && s.Owner == gp.floor) && overlaps(gp.Rect, s.Rect) {
+				gp.Kill()
+				return
+			}
+		}
+	}
+
+	if gp.ground && !wasGround && gp.OnLand != nil {
+		gp.OnLand(fallSpeed)
+	}
+	if st, ok := gp.floor.(Stander); ok && gp.ground && !gp.simulated {
+		st.StoodOn()
+	}
+	if gp.ground {
+		gp.coyote = gp.move.CoyoteTime
+	} else {
+		gp.coyote -= dt
+	}
+	gp.touchWalls(surfaces)
+	if gp.ground || gp.wall != 0 {
+		gp.wallJump = false
+	}
+
+	// releasing jump while rising cuts the jump short
+	if gp.jumping && (!controls.JumpHeld || gp.Vel.Y <= 0) {
+		if gp.Vel.Y > 0 {
+			gp.Vel.Y *= gp.move.JumpCut
+		}
+		gp.jumping = false
+		gp.hang = controls.JumpHeld
+	}
+	if gp.hang && (!controls.JumpHeld || gp.ground || gp.Vel.Y <= -gp.move.ApexSpeed) {
+		gp.hang = false
+	}
+
+	// jump if on the ground, or just off it, and the player wants to jump
+	switch {
+	case gp.buffered <= 0:
+	case gp.mayJump():
+		// keep the momentum of the moving ground
+		gp.Vel.X += carry.X
+		gp.Vel.Y = gp.move.JumpSpeed + math.Max(0, carry.Y)
+		gp.buffered, gp.coyote = 0, 0
+		gp.jumping = true
+	case gp.wall != 0:
+		// jump away from the wall, the controls can't pull the gopher back to it for a moment
+		gp.Vel = pixel.V(-gp.wall*gp.move.WallJumpPush, gp.move.WallJumpSpeed)
+		gp.buffered = 0
+		gp.jumping = true
+		gp.wallJump = true
+		gp.lockout = gp.move.WallJumpLock
+	}
+
+	for _, a := range gp.abilities {
+		a.AfterMove(gp, dt)
+	}
+	gp.touch()
+	gp.buffered -= dt
+}
+
+// bounce returns the speed after hitting a surface at speed, reversed if the surface is bouncy enough, else 0
+func bounce(speed, restitution float64) float64 {
+	if math.Abs(speed*restitution) < minBounceSpeed {
+		return 0
+	}
+	return -speed * restitution
+}
+
+// carried returns the velocity of the ground the gopher stands on
+func (gp *gopherPhys) carried(surfaces []Surface) pixel.Vec {
+	if !gp.ground || gp.floor == nil {
+		return pixel.ZV
+	}
+	for _, s := range surfaces {
+		if s.Owner == gp.floor && s.Vel != pixel.ZV {
+			return s.Vel
+		}
+	}
+	return pixel.ZV
+}
+
+// touchWalls finds the side of the gopher touching the side of a solid surface, if any
+func (gp *gopherPhys) touchWalls(surfaces []Surface) {
+	gp.wall = 0
+	if gp.ground {
+		return
+	}
+	for _, s := range surfaces {
+		if s.OneWay || gp.Rect.Max.Y-s.Rect.Min.Y <= overlapEpsilon || s.Rect.Max.Y-gp.Rect.Min.Y <= overlapEpsilon {
+			continue
+		}
+		switch {
+		case math.Abs(gp.Rect.Min.X-s.Rect.Max.X) <= wallReach:
+			gp.wall = -1
+		case math.Abs(s.Rect.Min.X-gp.Rect.Max.X) <= wallReach:
+			gp.wall = +1
+		}
+	}
+}
+
+// sliding reports whether the gopher falls along a wall it pushes against
+func (gp *gopherPhys) sliding() bool {
+	return !gp.ground && gp.wall != 0 && gp.wall*controls.Controls.X > 0 && gp.Vel.Y <= 0
+}
+
+// canJump reports whether pressing jump now jumps, from the ground, off a wall or with an ability
+func (gp *gopherPhys) canJump() bool {
+	if gp.mayJump() || gp.wall != 0 {
+		return true
+	}
+	for _, a := range gp.abilities {
+		if aj, ok := a.(AirJumper); ok && aj.CanAirJump(gp) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayJump reports whether the gopher is on the ground or just walked off it
+func (gp *gopherPhys) mayJump() bool {
+	return gp.ground || gp.coyote > 0
+}
+
+// run accelerates the gopher towards the run speed in the direction of the controls,
+// or slows it down to a stop, depending on the ground it stands on
+func (gp *gopherPhys) run(dt float64) {
+	if gp.lockout > 0 {
+		gp.lockout -= dt
+		return
+	}
+	m := gp.move
+	dir := 0.0
+	switch {
+	case controls.Controls.X < 0:
+		dir = -1
+	case controls.Controls.X > 0:
+		dir = +1
+	}
+
+	target := dir * m.RunSpeed
+	accel := m.Accel
+	if dir == 0 {
+		accel = m.Decel
+	}
+	if dir*gp.Vel.X < 0 {
+		// turning around
+		accel *= m.TurnBoost
+	}
+	if gp.ground {
+		mat := gp.material
+		if mat == nil {
+			mat = MaterialNormal
 		}
+		target *= mat.Speed
+		accel *= mat.Friction
+	} else {
+		// keep the speed of the jump unless the player steers
+		if dir == 0 {
+			return
+		}
+		accel *= m.AirControl
 	}
 
-	// jump if on the ground and the player wants to jump
-	if gp.ground && controls.Controls.Y > 0 {
-		gp.Vel.Y = gp.jumpSpeed
+	// move the velocity towards the target without going past it
+	dv := target - gp.Vel.X
+	if step := accel * dt; math.Abs(dv) > step {
+		dv = math.Copysign(step, dv)
 	}
+	gp.Vel.X += dv
+}
+
+// moveX moves the left side of the gopher at x
+func (gp *gopherPhys) moveX(x float64) {
+	gp.Rect = pixel.R(x, gp.Rect.Min.Y, x+gp.Rect.W(), gp.Rect.Max.Y)
+}
+
+// moveY moves the bottom of the gopher at y
+func (gp *gopherPhys) moveY(y float64) {
+	gp.Rect = pixel.R(gp.Rect.Min.X, y, gp.Rect.Max.X, y+gp.Rect.H())
 }
 
-func (gp *gopherPhys) collide(col colliders.Collider, dt) *colliders.CollisionInfo {
+func (gp *gopherPhys) collide(col colliders.Collider, dt float64) *colliders.CollisionInfo {
 	r := colliders.Rect(gp.Rect)
-	rmoved := colliders.Rect(gp.Rect).Grow(0, - gp.Vel.Y*dt, 0, 0)
+	rmoved := colliders.Rect(gp.Rect).Grow(0, -gp.Vel.Y*dt, 0, 0)
 	r.Contains(col)
 	rmoved.Contains(col)
-	// TODO: 
+	// TODO:
 	return nil
 }
 
 func (ga *gopherAnim) Update(dt float64) {
+	if ga.Phys.dead {
+		ga.die(dt)
+		return
+	}
 	ga.counter += dt
 	ga.Phys.update(dt)
+	if ga.Phys.dead {
+		// killed during this update
+		ga.startDying()
+		return
+	}
 
 	// determine the new animation state
 	var newState animState
 	switch {
+	case ga.Phys.sliding():
+		newState = wallSliding
+	case ga.Phys.wallJump && ga.Phys.Vel.Y > 0:
+		newState = wallJumping
 	case !ga.Phys.ground:
 		newState = jumping
 	case ga.Phys.Vel.Len() == 0:
@@ -121,8 +412,9 @@ func (ga *gopherAnim) Update(dt float64) {
 		i := int(math.Floor(ga.counter / ga.rate))
 		ga.frame = ga.anims["Run"][i%len(ga.anims["Run"])]
 	case jumping:
+		// the frames go from the take off to the landing, at the jump speed of the movement
 		speed := ga.Phys.Vel.Y
-		i := int((-speed/ga.Phys.jumpSpeed + 1) / 2 * float64(len(ga.anims["Jump"])))
+		i := int((-speed/ga.Phys.move.JumpSpeed + 1) / 2 * float64(len(ga.anims["Jump"])))
 		if i < 0 {
 			i = 0
 		}
@@ -130,10 +422,18 @@ func (ga *gopherAnim) Update(dt float64) {
 			i = len(ga.anims["Jump"]) - 1
 		}
 		ga.frame = ga.anims["Jump"][i]
+	case wallSliding:
+		i := int(math.Floor(ga.counter / ga.rate))
+		ga.frame = ga.animFrame("WallSlide", "Jump", i)
+	case wallJumping:
+		i := int(math.Floor(ga.counter / ga.rate))
+		ga.frame = ga.animFrame("WallJump", "Jump", i)
 	}
 
-	// set the facing direction of the gopher
-	if ga.Phys.Vel.X != 0 {
+	// set the facing direction of the gopher, away from the wall it slides along
+	if ga.state == wallSliding {
+		ga.dir = -ga.Phys.wall
+	} else if ga.Phys.Vel.X != 0 {
 		if ga.Phys.Vel.X > 0 {
 			ga.dir = +1
 		} else {
@@ -142,10 +442,125 @@ func (ga *gopherAnim) Update(dt float64) {
 	}
 }
 
-func (ga *gopherAnim) Draw(imd *imdraw.IMDraw) {
-	if ga.sprite == nil {
-		ga.sprite = pixel.NewSprite(nil, pixel.Rect{})
+// animFrame returns the frame i of the animation, looping, or of the fallback animation
+// for sheets made before the animation was added
+func (ga *gopherAnim) animFrame(name, fallback string, i int) pixel.Rect {
+	frames := ga.anims[name]
+	if len(frames) == 0 {
+		frames = ga.anims[fallback]
+	}
+	return frames[i%len(frames)]
+}
+
+func (ga *gopherAnim) Init(s *scene) {
+	ga.sprite = pixel.NewSprite(nil, pixel.Rect{})
+	ga.frame = ga.anims["Front"][0]
+}
+
+func (ga *gopherAnim) EnterScene(s *scene) {
+	// restart the animation timers
+	ga.state = idle
+	ga.counter = 0
+}
+
+// die plays the death of the gopher, hopping and falling through everything, then respawns it
+func (ga *gopherAnim) die(dt float64) {
+	ga.dying += dt
+	if ga.dying >= deathTime {
+		ga.Respawn()
+		return
+	}
+	ga.Phys.Vel.Y += ga.Phys.move.Gravity * dt
+	ga.Phys.Rect = ga.Phys.Rect.Moved(ga.Phys.Vel.Scaled(dt))
+}
+
+func (ga *gopherAnim) Dead() bool {
+	return ga.Phys.dead
+}
+
+// Kill kills the gopher like a hazard
+func (ga *gopherAnim) Kill() {
+	if ga.Phys.dead {
+		return
+	}
+	ga.Phys.Kill()
+	ga.startDying()
+}
+
+func (ga *gopherAnim) startDying() {
+	ga.dying = 0
+	ga.Phys.Vel = pixel.V(0, deathHop)
+	ga.frame = ga.anims["Front"][0]
+	Game.died()
+	if ga.OnDeath != nil {
+		ga.OnDeath()
+	}
+}
+
+// SetSpawn moves the point where the gopher comes back after dying
+func (ga *gopherAnim) SetSpawn(pos pixel.Vec) {
+	ga.Phys.Spawn = pos
+}
+
+// Respawn brings the gopher back at its spawn point, alive and still, like it just appeared
+func (ga *gopherAnim) Respawn() {
+	gp := ga.Phys
+	*gp = gopherPhys{
+		move:      gp.move,
+		Rect:      gp.Rect,
+		abilities: gp.abilities,
+		OnLand:    gp.OnLand,
+		Spawn:     gp.Spawn,
+	}
+	for _, a := range gp.abilities {
+		if r, ok := a.(Resetter); ok {
+			r.Reset()
+		}
+	}
+	// only the checkpoint the gopher comes back to stays up, restarting the level lowers them all
+	if s := Game.currentScene; s != nil {
+		for _, c := range FindObjects[*checkpoint](s) {
+			c.Active = c.spawn(gp) == gp.Spawn
+		}
 	}
+	ga.Teleport(gp.Spawn)
+	ga.state = idle
+	ga.counter = 0
+	ga.dying = 0
+	ga.frame = ga.anims["Front"][0]
+	if ga.OnRespawn != nil {
+		ga.OnRespawn()
+	}
+}
+
+// Teleport moves the center of the gopher to pos and stops it
+func (ga *gopherAnim) Teleport(pos pixel.Vec) {
+	ga.Phys.Rect = ga.Phys.Rect.Moved(pos.Sub(ga.Phys.Rect.Center()))
+	ga.Phys.Vel = pixel.ZV
+}
+
+func (ga *gopherAnim) Center() pixel.Vec {
+	return ga.Phys.Rect.Center()
+}
+
+func (ga *gopherAnim) Velocity() pixel.Vec {
+	return ga.Phys.Vel
+}
+
+func (ga *gopherAnim) OnGround() bool {
+	return ga.Phys.ground
+}
+
+func (ga *gopherAnim) Bounds() pixel.Rect {
+	return ga.Phys.Rect
+}
+
+// the gopher is drawn on top of the other actors
+func (ga *gopherAnim) DrawLayer() (Layer, int) {
+	return LayerActors, 1
+}
+
+func (ga *gopherAnim) Draw(imd *imdraw.IMDraw) {
 	// draw the correct frame with the correct position and direction
 	ga.sprite.Set(ga.sheet, ga.frame)
 	ga.sprite.Draw(imd, pixel.IM.
@@ -153,22 +568,30 @@ func (ga *gopherAnim) Draw(imd *imdraw.IMDraw) {
 			ga.Phys.Rect.W()/ga.sprite.Frame().W(),
 			ga.Phys.Rect.H()/ga.sprite.Frame().H(),
 		)).
-		ScaledXY(pixel.ZV, pixel.V(-ga.dir, 1)).
+		ScaledXY(pixel.ZV, pixel.V(-ga.dir, ga.upright())).
 		Moved(ga.Phys.Rect.Center()),
 	)
 }
 
+// upright is 1, or -1 to draw the dead gopher upside down
+func (ga *gopherAnim) upright() float64 {
+	if ga.Phys.dead {
+		return -1
+	}
+	return 1
+}
+
 func (ga *gopherAnim) Collide(col colliders.Collider) *colliders.CollisionInfo {
-	
+
 	return nil
 }
 
-func NewGopher(sheet pixel.Picture, anims map[string][]pixel.Rect) *gopherAnim {
+// NewGopher creates the gopher centered on the origin with the DefaultMovement, changed by the options.
+// It fails on the first option making the movement invalid.
+func NewGopher(sheet pixel.Picture, anims map[string][]pixel.Rect, opts ...GopherOption) (*gopherAnim, error) {
 	phys := &gopherPhys{
-		gravity:   -512,
-		runSpeed:  64,
-		jumpSpeed: 192,
-		Rect:      colliders.R(-6, -7, 6, 7),
+		move: DefaultMovement(),
+		Rect: pixel.Rect(colliders.R(-6, -7, 6, 7)),
 	}
 
 	anim := &gopherAnim{
@@ -178,5 +601,10 @@ func NewGopher(sheet pixel.Picture, anims map[string][]pixel.Rect) *gopherAnim {
 		dir:   +1,
 		Phys:  phys,
 	}
-	return anim
+	for _, opt := range opts {
+		if err := opt(anim); err != nil {
+			return nil, errors.Wrap(err, "error creating the gopher")
+		}
+	}
+	return anim, nil
 }
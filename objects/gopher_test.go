@@ -0,0 +1,329 @@
+package objects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/controls"
+)
+
+const testDt = 1.0 / 60
+
+var testAnims = map[string][]pixel.Rect{
+	"Front": {pixel.R(0, 0, 12, 14)},
+	"Run":   {pixel.R(0, 0, 12, 14)},
+	"Jump":  {pixel.R(0, 0, 12, 14)},
+}
+
+// newTestGopher puts a gopher standing on the floor at x, the top of the floor at y=0,
+// in a new current scene with the other objects
+func newTestGopher(t *testing.T, floor pixel.Rect, x float64, objs ...Object) *gopherPhys {
+	t.Helper()
+	resetControls()
+	t.Cleanup(resetControls)
+
+	s := NewScene()
+	ga := newGopher(t)
+	s.AddObjects(append([]Object{ga, NewPlatform(floor)}, objs...)...)
+	Game.SetCurrentScene(s)
+	t.Cleanup(func() { Game.SetCurrentScene(nil) })
+
+	ga.Teleport(pixel.V(x, ga.Phys.Rect.H()/2))
+	gp := ga.Phys
+	// settle on the floor
+	gp.update(testDt)
+	if !gp.ground {
+		t.Fatalf("the gopher is not on the floor: %v", gp.Rect)
+	}
+	return gp
+}
+
+func newGopher(t *testing.T, opts ...GopherOption) *gopherAnim {
+	t.Helper()
+	ga, err := NewGopher(nil, testAnims, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ga
+}
+
+func resetControls() {
+	controls.Controls = pixel.ZV
+	controls.JumpHeld, controls.Dash, controls.Down, controls.Fire = false, false, false, false
+}
+
+// pressJump presses jump for one step of dt and holds it
+func pressJump(gp *gopherPhys, dt float64) {
+	controls.Controls.Y = 1
+	controls.JumpHeld = true
+	gp.update(dt)
+	controls.Controls.Y = 0
+}
+
+// peak steps the gopher until it lands and returns how high its feet went
+func peak(t *testing.T, gp *gopherPhys, floor float64) float64 {
+	t.Helper()
+	top := gp.Rect.Min.Y
+	for i := 0; i < 600; i++ {
+		gp.update(testDt)
+		top = math.Max(top, gp.Rect.Min.Y)
+		if gp.ground {
+			return top - floor
+		}
+	}
+	t.Fatal("the gopher never landed")
+	return 0
+}
+
+func TestJumpBuffer(t *testing.T) {
+	m := DefaultMovement()
+	for _, tc := range []struct {
+		name   string
+		before float64 // seconds jump is pressed before landing
+		jumps  bool
+	}{
+		{"just before landing", m.JumpBuffer / 2, true},
+		{"too early", m.JumpBuffer * 3, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gp := newTestGopher(t, pixel.R(-100, -2, 100, 0), 0)
+			gp.Vel.Y = -120
+			// fall from where the gopher is tc.before seconds away from the floor
+			gp.moveY(120 * tc.before)
+			gp.ground, gp.coyote = false, 0
+			pressJump(gp, testDt)
+			controls.JumpHeld = false
+
+			jumped := false
+			for i := 0; i < 30; i++ {
+				gp.update(testDt)
+				jumped = jumped || gp.Vel.Y > 0
+			}
+			if jumped != tc.jumps {
+				t.Errorf("jumped %v, want %v", jumped, tc.jumps)
+			}
+		})
+	}
+}
+
+func TestCoyoteTime(t *testing.T) {
+	m := DefaultMovement()
+	for _, tc := range []struct {
+		name  string
+		after float64 // seconds jump is pressed after walking off the edge
+		jumps bool
+	}{
+		{"just off the edge", m.CoyoteTime / 2, true},
+		{"too late", m.CoyoteTime * 2, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gp := newTestGopher(t, pixel.R(-100, -2, 0, 0), -20)
+			controls.Controls.X = 1
+			for gp.ground {
+				gp.update(testDt)
+			}
+			for left := tc.after; left > testDt/2; left -= testDt {
+				gp.update(testDt)
+			}
+			pressJump(gp, testDt)
+			if jumped := gp.Vel.Y > 0; jumped != tc.jumps {
+				t.Errorf("jumped %v, want %v", jumped, tc.jumps)
+			}
+		})
+	}
+}
+
+func TestJumpCut(t *testing.T) {
+	gp := newTestGopher(t, pixel.R(-100, -2, 100, 0), 0)
+	pressJump(gp, testDt)
+	full := peak(t, gp, 0)
+
+	pressJump(gp, testDt)
+	for i := 0; i < 3; i++ {
+		gp.update(testDt)
+	}
+	controls.JumpHeld = false
+	cut := peak(t, gp, 0)
+
+	if cut >= full*0.75 {
+		t.Errorf("releasing jump early went %.1f high, a full jump %.1f", cut, full)
+	}
+	if cut <= 0 {
+		t.Errorf("releasing jump early did not jump: %.1f", cut)
+	}
+}
+
+func TestApexHang(t *testing.T) {
+	for _, hang := range []float64{1, 0.5} {
+		gp := newTestGopher(t, pixel.R(-100, -2, 100, 0), 0)
+		m := gp.move
+		m.ApexHang = hang
+		gp.move = m
+
+		pressJump(gp, testDt)
+		slow := 0.0
+		for i := 0; i < 600; i++ {
+			gp.update(testDt)
+			if gp.ground {
+				break
+			}
+			if math.Abs(gp.Vel.Y) < m.ApexSpeed {
+				slow += testDt
+			}
+		}
+		// the speed goes from +ApexSpeed to -ApexSpeed under the scaled gravity
+		want := 2 * m.ApexSpeed / (-m.Gravity * hang)
+		if math.Abs(slow-want) > 2*testDt {
+			t.Errorf("hang %v: %.3fs near the apex, want about %.3fs", hang, slow, want)
+		}
+	}
+}
+
+func TestGopherOptions(t *testing.T) {
+	ga := newGopher(t, WithRunSpeed(80), WithHitbox(10, 12))
+	if m := ga.Movement(); m.RunSpeed != 80 || ga.Phys.Rect.W() != 10 || ga.Phys.Rect.H() != 12 {
+		t.Errorf("the options were not applied: %+v, hitbox %v", m, ga.Phys.Rect)
+	}
+
+	for i, opt := range []GopherOption{WithGravity(10), WithRunSpeed(0), WithJumpSpeed(-1), WithHitbox(0, 12), WithAnimRate(0)} {
+		if _, err := NewGopher(nil, testAnims, opt); err == nil {
+			t.Errorf("option %d: no error creating a gopher with an invalid option", i)
+		}
+	}
+
+	bad := ga.Movement()
+	bad.ApexHang = 0
+	if err := ga.SetMovement(bad); err == nil {
+		t.Error("no error setting an apex hang of 0")
+	}
+	if ga.Movement().ApexHang == 0 {
+		t.Error("the invalid movement replaced the movement of the gopher")
+	}
+}
+
+func TestDoubleJumpGrantedInTheAir(t *testing.T) {
+	gp := newTestGopher(t, pixel.R(-100, -2, 100, 0), 0)
+	ga := FindObjects[*gopherAnim](Game.currentScene)[0]
+	pressJump(gp, testDt)
+	controls.JumpHeld = false
+	for gp.Vel.Y >= 0 {
+		gp.update(testDt)
+	}
+	ga.Grant(NewDoubleJump())
+	pressJump(gp, testDt)
+	if gp.Vel.Y <= 0 {
+		t.Errorf("no double jump granted in the air, speed %v", gp.Vel)
+	}
+}
+
+// newCarryScene puts the gopher standing at x on top of ground, in a new current scene with the other objects
+func newCarryScene(t *testing.T, ground Object, top, x float64, objs ...Object) *gopherAnim {
+	t.Helper()
+	resetControls()
+	t.Cleanup(resetControls)
+	s := NewScene()
+	ga := newGopher(t)
+	s.AddObjects(append([]Object{ga, ground}, objs...)...)
+	Game.SetCurrentScene(s)
+	t.Cleanup(func() { Game.SetCurrentScene(nil) })
+	ga.Teleport(pixel.V(x, top+ga.Phys.Rect.H()/2))
+	return ga
+}
+
+func TestConveyorIntoWall(t *testing.T) {
+	belt := NewPlatform(pixel.R(-100, -2, 100, 0))
+	belt.Conveyor = 80
+	wall := NewPlatform(pixel.R(40, 0, 50, 40))
+	wall.OneWay = false
+	ga := newCarryScene(t, belt, 0, 0, wall)
+
+	for i := 0; i < 120; i++ {
+		Game.currentScene.Update(testDt)
+		if gp := ga.Phys; gp.Rect.Max.X > wall.Rect.Min.X+overlapEpsilon {
+			t.Fatalf("step %d: the belt carried the gopher into the wall, it is at %v", i, gp.Rect)
+		}
+	}
+	if gp := ga.Phys; !gp.ground || gp.Rect.Min.Y != 0 {
+		t.Errorf("the gopher is not standing on the belt, it is at %v", gp.Rect)
+	}
+}
+
+func TestCarriedIntoWall(t *testing.T) {
+	lift := NewMovingPlatform(pixel.R(0, 0, 40, 4), []pixel.Vec{pixel.V(0, 0), pixel.V(80, 0)}, 40)
+	wall := NewPlatform(pixel.R(30, 3, 40, 40))
+	wall.OneWay = false
+	ga := newCarryScene(t, lift, 2, 0, wall)
+
+	for i := 0; i < 60; i++ {
+		Game.currentScene.Update(testDt)
+		if gp := ga.Phys; gp.Rect.Max.X > wall.Rect.Min.X+overlapEpsilon {
+			t.Fatalf("step %d: the platform carried the gopher into the wall, it is at %v", i, gp.Rect)
+		}
+	}
+	if ga.Dead() {
+		t.Error("the gopher was crushed against the wall, the platform goes under it")
+	}
+}
+
+func TestCarriedIntoCeiling(t *testing.T) {
+	lift := NewMovingPlatform(pixel.R(0, 0, 40, 4), []pixel.Vec{pixel.V(0, 0), pixel.V(0, 40)}, 30)
+	ceiling := NewPlatform(pixel.R(-50, 30, 50, 40))
+	ceiling.OneWay = false
+	ga := newCarryScene(t, lift, 2, 0, ceiling)
+
+	for i := 0; i < 120 && !ga.Dead(); i++ {
+		Game.currentScene.Update(testDt)
+		if gp := ga.Phys; !gp.dead && overlaps(gp.Rect, ceiling.Rect) {
+			t.Fatalf("step %d: the platform lifted the gopher into the ceiling, it is at %v", i, gp.Rect)
+		}
+	}
+	if !ga.Dead() {
+		t.Errorf("the gopher was not crushed against the ceiling, it is at %v", ga.Phys.Rect)
+	}
+}
+
+func TestRespawn(t *testing.T) {
+	resetControls()
+	t.Cleanup(resetControls)
+	s := NewScene()
+	ga := newGopher(t)
+	pound := NewGroundPound()
+	ga.Grant(pound)
+	first, second := NewCheckpoint(pixel.V(20, 0)), NewCheckpoint(pixel.V(60, 0))
+	s.AddObjects(ga, NewPlatform(pixel.R(-100, -2, 100, 0)), first, second)
+	Game.SetCurrentScene(s)
+	t.Cleanup(func() { Game.SetCurrentScene(nil) })
+
+	// die in the middle of a dive after touching the first checkpoint
+	first.Touch(ga.Phys)
+	ga.Teleport(pixel.V(0, 50))
+	controls.Down = true
+	ga.Phys.update(testDt)
+	controls.Down = false
+	if !pound.Pounding() {
+		t.Fatal("the gopher is not pounding")
+	}
+	ga.Kill()
+	ga.Respawn()
+	if pound.Pounding() {
+		t.Error("still pounding after respawning")
+	}
+	if !first.Active || second.Active {
+		t.Errorf("the checkpoints came back %v and %v, want only the first up", first.Active, second.Active)
+	}
+
+	// restart the level
+	ga.SetSpawn(pixel.V(0, 7))
+	ga.Respawn()
+	if first.Active || second.Active {
+		t.Errorf("the checkpoints stayed %v and %v after restarting, want both down", first.Active, second.Active)
+	}
+
+	first.Touch(ga.Phys)
+	s.exit()
+	s.enter()
+	if first.Active {
+		t.Error("the checkpoint stayed up entering the level again")
+	}
+}
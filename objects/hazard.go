@@ -0,0 +1,154 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// spikes kill the gopher touching them, they point up
+type spikes struct {
+	Rect  pixel.Rect
+	Color pixel.RGBA
+}
+
+func (s *spikes) Touch(gp *gopherPhys) {
+	gp.Kill()
+}
+
+func (s *spikes) Update(dt float64) {}
+
+func (s *spikes) Bounds() pixel.Rect {
+	return s.Rect
+}
+
+func (s *spikes) DrawLayer() (Layer, int) {
+	return LayerTerrain, 1
+}
+
+// Draw draws a row of triangles as high as the spikes
+func (s *spikes) Draw(imd *imdraw.IMDraw) {
+	imd.Color = s.Color
+	n := math.Max(1, math.Round(s.Rect.W()/s.Rect.H()))
+	w := s.Rect.W() / n
+	for x := s.Rect.Min.X; x+w/2 < s.Rect.Max.X; x += w {
+		imd.Push(pixel.V(x, s.Rect.Min.Y), pixel.V(x+w/2, s.Rect.Max.Y), pixel.V(x+w, s.Rect.Min.Y))
+		imd.Polygon(0)
+	}
+}
+
+func (s *spikes) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return colliders.Rect(s.Rect).Contains(col)
+}
+
+func NewSpikes(r pixel.Rect) *spikes {
+	return &spikes{Rect: r, Color: pixel.RGB(0.8, 0.8, 0.85)}
+}
+
+// killPlane kills the gopher falling below Y
+type killPlane struct {
+	Y float64
+}
+
+func (k *killPlane) Touch(gp *gopherPhys) {
+	gp.Kill()
+}
+
+func (k *killPlane) Update(dt float64) {}
+
+func (k *killPlane) Bounds() pixel.Rect {
+	return pixel.R(-math.MaxFloat64, -math.MaxFloat64, math.MaxFloat64, k.Y)
+}
+
+func (k *killPlane) Draw(imd *imdraw.IMDraw) {}
+
+func (k *killPlane) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+func NewKillPlane(y float64) *killPlane {
+	return &killPlane{Y: y}
+}
+
+// KillPlaneBelow creates a kill plane margin below the lowest surface of the scene
+func KillPlaneBelow(s *scene, margin float64) *killPlane {
+	low := 0.0
+	for i, sf := range s.Surfaces() {
+		if i == 0 || sf.Rect.Min.Y < low {
+			low = sf.Rect.Min.Y
+		}
+	}
+	return NewKillPlane(low - margin)
+}
+
+// checkpoint sets where the gopher comes back after dying, the last one touched is active
+type checkpoint struct {
+	Pos    pixel.Vec // bottom of the pole
+	Active bool
+
+	scene *scene
+	raise float64 // height of the flag, from 0 to 1
+}
+
+func (c *checkpoint) Init(s *scene) {
+	c.scene = s
+}
+
+// EnterScene lowers the flag, the level starts over
+func (c *checkpoint) EnterScene(s *scene) {
+	c.Active, c.raise = false, 0
+}
+
+// spawn is where the gopher comes back after touching the checkpoint, standing where the pole is
+func (c *checkpoint) spawn(gp *gopherPhys) pixel.Vec {
+	return c.Pos.Add(pixel.V(0, gp.Rect.H()/2))
+}
+
+func (c *checkpoint) Touch(gp *gopherPhys) {
+	gp.Spawn = c.spawn(gp)
+	if gp.simulated || c.Active {
+		return
+	}
+	for _, other := range FindObjects[*checkpoint](c.scene) {
+		other.Active = false
+	}
+	c.Active = true
+}
+
+func (c *checkpoint) Update(dt float64) {
+	if c.Active {
+		c.raise = math.Min(1, c.raise+dt*2)
+	} else {
+		c.raise = math.Max(0, c.raise-dt*2)
+	}
+}
+
+func (c *checkpoint) Bounds() pixel.Rect {
+	return pixel.R(c.Pos.X-4, c.Pos.Y, c.Pos.X+4, c.Pos.Y+16)
+}
+
+func (c *checkpoint) DrawLayer() (Layer, int) {
+	return LayerActors, 0
+}
+
+// Draw draws a pole with a flag going up once the checkpoint is active
+func (c *checkpoint) Draw(imd *imdraw.IMDraw) {
+	imd.Color = pixel.RGB(0.7, 0.7, 0.7)
+	imd.Push(c.Pos, c.Pos.Add(pixel.V(0, 16)))
+	imd.Line(1)
+
+	y := c.Pos.Y + 2 + 10*c.raise
+	imd.Color = pixel.RGB(0.9, 0.2, 0.2).Scaled(1 - c.raise).Add(pixel.RGB(0.2, 0.9, 0.3).Scaled(c.raise))
+	imd.Push(pixel.V(c.Pos.X, y), pixel.V(c.Pos.X+6, y+2), pixel.V(c.Pos.X, y+4))
+	imd.Polygon(0)
+}
+
+func (c *checkpoint) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+func NewCheckpoint(pos pixel.Vec) *checkpoint {
+	return &checkpoint{Pos: pos}
+}
@@ -0,0 +1,116 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// jumpMargin is the part of the ideal jump distance considered safe,
+// it covers the take off, not always right at the edge, and the landing check of the physics
+const jumpMargin = 0.85
+
+// MaxStep is the longest time step of the physics, the scene cuts longer frames to it
+const MaxStep = 1.0 / 30
+
+// jumpParams are the physics of an actor used to tell which jumps it can make
+type jumpParams struct {
+	gravity   float64 // negative
+	runSpeed  float64
+	jumpSpeed float64
+	size      pixel.Vec // size of the hitbox
+}
+
+func (gp *gopherPhys) jumpParams() jumpParams {
+	return jumpParams{
+		gravity:   gp.move.Gravity,
+		runSpeed:  gp.move.RunSpeed,
+		jumpSpeed: gp.move.JumpSpeed,
+		size:      gp.Rect.Size(),
+	}
+}
+
+func (ga *gopherAnim) jumpParams() jumpParams {
+	return ga.Phys.jumpParams()
+}
+
+// speed is the jump speed of the continuous jump the physics follow, stepping by up to MaxStep.
+// Adding the gravity to the speed before moving, each step lags behind the ideal jump as if it was gravity*dt/2 slower.
+func (jp jumpParams) speed() float64 {
+	return jp.jumpSpeed + jp.gravity*MaxStep/2
+}
+
+// apex is how high the feet surely go above the take off, the highest step may miss the top by gravity*dt²/8
+func (jp jumpParams) apex() float64 {
+	v := jp.speed()
+	return v*v/(-2*jp.gravity) + jp.gravity*MaxStep*MaxStep/8
+}
+
+// airTime is how long a jump takes to fall back to dh above the take off, false if dh is above the apex
+func (jp jumpParams) airTime(dh float64) (float64, bool) {
+	g, v := -jp.gravity, jp.speed()
+	disc := v*v - 2*g*dh
+	if disc < 0 {
+		return 0, false
+	}
+	return (v + math.Sqrt(disc)) / g, true
+}
+
+// reach is the safe horizontal distance covered by a jump landing dh above the take off,
+// false if dh is out of reach
+func (jp jumpParams) reach(dh float64) (float64, bool) {
+	if dh > jp.apex() {
+		return 0, false
+	}
+	t, ok := jp.airTime(dh)
+	if !ok {
+		return 0, false
+	}
+	return jp.runSpeed * t * jumpMargin, true
+}
+
+// maxGap is the widest gap between two surface edges an actor can jump over, landing dh above the take off.
+// The actor can take off with most of its hitbox past the edge and land with only a bit of it on the target.
+func (jp jumpParams) maxGap(dh float64) (float64, bool) {
+	r, ok := jp.reach(dh)
+	if !ok {
+		return 0, false
+	}
+	return r + jp.size.X, true
+}
+
+// canJump reports whether an actor standing on from can land on to, running and jumping at most once.
+// It ignores what is between the two surfaces.
+func (jp jumpParams) canJump(from, to Surface) bool {
+	dh := to.Rect.Max.Y - from.Rect.Max.Y
+	// sticky ground slows the run up, faster ground needs a long run up so it is not counted
+	jp.runSpeed *= math.Min(1, from.material().Speed)
+
+	var gap float64
+	switch {
+	case to.Rect.Min.X >= from.Rect.Max.X:
+		gap = to.Rect.Min.X - from.Rect.Max.X
+	case to.Rect.Max.X <= from.Rect.Min.X:
+		gap = from.Rect.Min.X - to.Rect.Max.X
+	default:
+		// the surfaces overlap horizontally
+		if dh > 0 && to.OneWay {
+			// jump through it from below
+			_, ok := jp.reach(dh)
+			return ok
+		}
+		// walk off (or jump from) an edge of from sticking out of to
+		out := math.Max(to.Rect.Max.X-from.Rect.Max.X, from.Rect.Min.X-to.Rect.Min.X)
+		if out < jp.size.X && dh <= 0 {
+			return false
+		}
+		// or go around to, a gap of the width of the hitbox is enough to fall by its side
+		gap = 0
+		if dh > 0 {
+			gap = jp.size.X
+		}
+	}
+
+	maxGap, ok := jp.maxGap(dh)
+	return ok && gap <= maxGap
+}
@@ -0,0 +1,49 @@
+package objects
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/controls"
+)
+
+// the gopher runs to the edge of a platform and jumps on the last step before running off it, holding jump,
+// across the widest gap jumpParams allows
+func TestMaxGapJumpable(t *testing.T) {
+	jp := newGopher(t).Phys.jumpParams()
+	for _, dt := range []float64{1.0 / 60, MaxStep} {
+		for _, dh := range []float64{-60, -20, 0, jp.apex() / 2, jp.apex()} {
+			t.Run(fmt.Sprintf("dt %.3f dh %.1f", dt, dh), func(t *testing.T) {
+				gap, ok := jp.maxGap(dh)
+				if !ok {
+					t.Fatalf("no gap for dh %v", dh)
+				}
+				target := pixel.R(gap, dh-2, gap+40, dh)
+				gp := newTestGopher(t, pixel.R(-100, -2, 0, 0), -90, NewPlatform(target))
+
+				controls.Controls.X = 1
+				// the next step would still be on the edge, the one after runs off it
+				for gp.Rect.Min.X+2*gp.Vel.X*dt < -overlapEpsilon {
+					if !gp.ground {
+						t.Fatal("the gopher fell before the edge")
+					}
+					gp.update(dt)
+				}
+				pressJump(gp, dt)
+				for i := 0; ; i++ {
+					if i > 600 || gp.Rect.Max.Y < dh-100 {
+						t.Fatalf("the gopher missed the platform %v, it is at %v", target, gp.Rect)
+					}
+					gp.update(dt)
+					if gp.ground {
+						break
+					}
+				}
+				if gp.Rect.Min.Y != dh {
+					t.Errorf("the gopher landed at %v, not on the platform %v", gp.Rect, target)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package objects
+
+import "strconv"
+
+// Layer groups the objects of a scene when drawing, lower layers are drawn first
+type Layer int
+
+const (
+	LayerBackground Layer = iota
+	LayerTerrain
+	LayerActors
+	LayerForeground
+	LayerUI
+)
+
+var layerNames = map[Layer]string{
+	LayerBackground: "background",
+	LayerTerrain:    "terrain",
+	LayerActors:     "actors",
+	LayerForeground: "foreground",
+	LayerUI:         "ui",
+}
+
+func (l Layer) String() string {
+	if name, ok := layerNames[l]; ok {
+		return name
+	}
+	return "layer" + strconv.Itoa(int(l))
+}
+
+// ParseLayer returns the layer with the given name
+func ParseLayer(name string) (Layer, bool) {
+	for l, n := range layerNames {
+		if n == name {
+			return l, true
+		}
+	}
+	return 0, false
+}
+
+// Layered objects choose their default draw layer and their z-index inside that layer,
+// objects with the same layer and z-index are drawn in insertion order.
+// Objects not implementing it are drawn in LayerActors with a z-index of 0.
+type Layered interface {
+	DrawLayer() (layer Layer, z int)
+}
@@ -0,0 +1,31 @@
+package objects
+
+// Material is how the ground of a surface changes the running of actors standing on it
+type Material struct {
+	Name string
+	// Friction scales the acceleration and the deceleration of actors, slippery below 1
+	Friction float64
+	// Speed scales the run speed of actors, sticky below 1
+	Speed float64
+}
+
+var (
+	MaterialNormal = &Material{Name: "normal", Friction: 1, Speed: 1}
+	MaterialIce    = &Material{Name: "ice", Friction: 0.1, Speed: 1.25}
+	MaterialMud    = &Material{Name: "mud", Friction: 3, Speed: 0.5}
+)
+
+// Materials are the materials by name, the names used in level files
+var Materials = map[string]*Material{
+	MaterialNormal.Name: MaterialNormal,
+	MaterialIce.Name:    MaterialIce,
+	MaterialMud.Name:    MaterialMud,
+}
+
+// material returns the material of the surface, surfaces without one are normal ground
+func (s *Surface) material() *Material {
+	if s.Material == nil {
+		return MaterialNormal
+	}
+	return s.Material
+}
@@ -0,0 +1,162 @@
+package objects
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// Movement is the tunable physics of the gopher, the JSON names are the ones of movement files
+type Movement struct {
+	Gravity   float64 `json:"gravity"` // negative, pulls down
+	RunSpeed  float64 `json:"runSpeed"`
+	JumpSpeed float64 `json:"jumpSpeed"`
+	// how fast the gopher gets to its run speed and stops on normal ground, per second
+	Accel float64 `json:"accel"`
+	Decel float64 `json:"decel"`
+	// part of the acceleration the gopher keeps in the air
+	AirControl float64 `json:"airControl"`
+	// multiplies the acceleration when running against the current velocity
+	TurnBoost float64 `json:"turnBoost"`
+	// time after walking off a ledge during which the gopher can still jump
+	CoyoteTime float64 `json:"coyoteTime"`
+	// time a jump pressed in the air is remembered, to jump as soon as the gopher lands
+	JumpBuffer float64 `json:"jumpBuffer"`
+	// multiplies the rising speed when jump is released early
+	JumpCut float64 `json:"jumpCut"`
+	// multiplies the gravity near the apex of a held jump, while the vertical speed is below ApexSpeed
+	ApexHang  float64 `json:"apexHang"`
+	ApexSpeed float64 `json:"apexSpeed"`
+	// fastest fall while pushing against a wall
+	WallSlideSpeed float64 `json:"wallSlideSpeed"`
+	// speed of a jump off a wall, up and away from it
+	WallJumpSpeed float64 `json:"wallJumpSpeed"`
+	WallJumpPush  float64 `json:"wallJumpPush"`
+	// time after a wall jump during which the controls don't steer the gopher
+	WallJumpLock float64 `json:"wallJumpLock"`
+	// size of the hitbox
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// DefaultMovement is the movement the gopher was tuned with
+func DefaultMovement() Movement {
+	return Movement{
+		Gravity:    -512,
+		RunSpeed:   64,
+		JumpSpeed:  192,
+		Accel:      1024,
+		Decel:      1536,
+		AirControl: 0.6,
+		TurnBoost:  2,
+		CoyoteTime: 0.1,
+		JumpBuffer: 0.1,
+		JumpCut:    0.5,
+		ApexHang:   0.5,
+		ApexSpeed:  24,
+
+		WallSlideSpeed: 48,
+		WallJumpSpeed:  180,
+		WallJumpPush:   96,
+		WallJumpLock:   0.15,
+		Width:          12,
+		Height:         14,
+	}
+}
+
+// Validate returns an error when the gopher could not move with m
+func (m Movement) Validate() error {
+	switch {
+	case m.Gravity >= 0:
+		return errors.Errorf("gravity must be negative, got %v", m.Gravity)
+	case m.RunSpeed <= 0:
+		return errors.Errorf("runSpeed must be positive, got %v", m.RunSpeed)
+	case m.JumpSpeed <= 0:
+		return errors.Errorf("jumpSpeed must be positive, got %v", m.JumpSpeed)
+	case m.Accel <= 0 || m.Decel <= 0:
+		return errors.Errorf("accel and decel must be positive, got %v and %v", m.Accel, m.Decel)
+	case m.AirControl < 0 || m.AirControl > 1:
+		return errors.Errorf("airControl must be between 0 and 1, got %v", m.AirControl)
+	case m.TurnBoost < 1:
+		return errors.Errorf("turnBoost must be at least 1, got %v", m.TurnBoost)
+	case m.CoyoteTime < 0 || m.JumpBuffer < 0:
+		return errors.Errorf("coyoteTime and jumpBuffer must not be negative, got %v and %v", m.CoyoteTime, m.JumpBuffer)
+	case m.JumpCut < 0 || m.JumpCut > 1:
+		return errors.Errorf("jumpCut must be between 0 and 1, got %v", m.JumpCut)
+	case m.ApexHang <= 0 || m.ApexHang > 1:
+		return errors.Errorf("apexHang must be above 0 and at most 1, got %v", m.ApexHang)
+	case m.ApexSpeed < 0:
+		return errors.Errorf("apexSpeed must not be negative, got %v", m.ApexSpeed)
+	case m.WallSlideSpeed <= 0:
+		return errors.Errorf("wallSlideSpeed must be positive, got %v", m.WallSlideSpeed)
+	case m.WallJumpSpeed < 0 || m.WallJumpPush < 0 || m.WallJumpLock < 0:
+		return errors.Errorf("wallJumpSpeed, wallJumpPush and wallJumpLock must not be negative, got %v, %v and %v",
+			m.WallJumpSpeed, m.WallJumpPush, m.WallJumpLock)
+	case m.Width <= 0 || m.Height <= 0:
+		return errors.Errorf("hitbox must not be empty, got %vx%v", m.Width, m.Height)
+	}
+	return nil
+}
+
+// GopherOption changes the gopher created by NewGopher, it fails when the movement would not be valid
+type GopherOption func(ga *gopherAnim) error
+
+// WithMovement replaces the whole movement of the gopher
+func WithMovement(m Movement) GopherOption {
+	return func(ga *gopherAnim) error { return ga.SetMovement(m) }
+}
+
+// withMove changes a part of the movement of the gopher with f
+func withMove(f func(m *Movement)) GopherOption {
+	return func(ga *gopherAnim) error {
+		m := ga.Phys.move
+		f(&m)
+		return ga.SetMovement(m)
+	}
+}
+
+func WithGravity(gravity float64) GopherOption {
+	return withMove(func(m *Movement) { m.Gravity = gravity })
+}
+
+func WithRunSpeed(speed float64) GopherOption {
+	return withMove(func(m *Movement) { m.RunSpeed = speed })
+}
+
+func WithJumpSpeed(speed float64) GopherOption {
+	return withMove(func(m *Movement) { m.JumpSpeed = speed })
+}
+
+func WithHitbox(width, height float64) GopherOption {
+	return withMove(func(m *Movement) { m.Width, m.Height = width, height })
+}
+
+// WithAnimRate sets the time each frame of the run animation is shown
+func WithAnimRate(rate float64) GopherOption {
+	return func(ga *gopherAnim) error {
+		if rate <= 0 {
+			return errors.Errorf("anim rate must be positive, got %v", rate)
+		}
+		ga.rate = rate
+		return nil
+	}
+}
+
+func (ga *gopherAnim) Movement() Movement {
+	return ga.Phys.move
+}
+
+// SetMovement changes the movement of the gopher while it plays, an invalid movement is refused and the gopher keeps its own.
+// A new hitbox keeps the feet where they are so the gopher does not fall through the ground.
+func (ga *gopherAnim) SetMovement(m Movement) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	gp := ga.Phys
+	gp.move = m
+	if gp.Rect.W() == m.Width && gp.Rect.H() == m.Height {
+		return nil
+	}
+	feet := pixel.V(gp.Rect.Center().X, gp.Rect.Min.Y)
+	gp.Rect = pixel.R(feet.X-m.Width/2, feet.Y, feet.X+m.Width/2, feet.Y+m.Height)
+	return nil
+}
@@ -0,0 +1,174 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// PathMode is what a moving platform does at the end of its path
+type PathMode int
+
+const (
+	// PathOnce stops at the last point
+	PathOnce PathMode = iota
+	// PathLoop goes from the last point back to the first one
+	PathLoop
+	// PathPingPong goes back along the path
+	PathPingPong
+)
+
+var pathModes = map[string]PathMode{"once": PathOnce, "loop": PathLoop, "pingpong": PathPingPong}
+
+// Easing maps the progress t along a segment, from 0 to 1, to the part of the segment travelled
+type Easing func(t float64) float64
+
+var (
+	EaseLinear Easing = func(t float64) float64 { return t }
+	// EaseInOut speeds up from each point and slows down to the next
+	EaseInOut Easing = func(t float64) float64 { return t * t * (3 - 2*t) }
+	// EaseInOutCubic is a stronger EaseInOut
+	EaseInOutCubic Easing = func(t float64) float64 {
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 1 - math.Pow(-2*t+2, 3)/2
+	}
+)
+
+var easings = map[string]Easing{"linear": EaseLinear, "inout": EaseInOut, "inoutcubic": EaseInOutCubic}
+
+// movingPlatform is a platform following a path of points, actors standing on it move along
+type movingPlatform struct {
+	*platform
+	// Path are the positions of the center of the platform
+	Path  []pixel.Vec
+	Mode  PathMode
+	Ease  Easing
+	Speed float64 // along the path, per second
+	Wait  float64 // time spent at each point
+
+	segment int     // moving from Path[segment] to the next point
+	t       float64 // time spent on the segment, including the wait
+	back    bool    // going back along the path in ping pong mode
+	done    bool
+	vel     pixel.Vec
+}
+
+// next returns the index of the point after i in the current direction, false at the end of the path
+func (m *movingPlatform) next(i int) (int, bool) {
+	switch {
+	case m.back && i > 0:
+		return i - 1, true
+	case !m.back && i < len(m.Path)-1:
+		return i + 1, true
+	case m.Mode == PathLoop:
+		return 0, true
+	}
+	return i, false
+}
+
+// PreUpdate moves the platform before the actors standing on it are updated
+func (m *movingPlatform) PreUpdate(dt float64) {
+	m.vel = pixel.ZV
+	if m.done || len(m.Path) < 2 || dt <= 0 {
+		return
+	}
+	old := m.Rect.Center()
+
+	m.t += dt
+	// a path of points all at the same place would never end, give up after going along it a few times
+	for steps := 0; steps <= 4*len(m.Path); steps++ {
+		to, ok := m.next(m.segment)
+		if !ok {
+			if m.Mode != PathPingPong {
+				m.done = true
+				break
+			}
+			m.back = !m.back
+			continue
+		}
+		length := m.Path[to].Sub(m.Path[m.segment]).Len()
+		duration := m.Wait + length/m.Speed
+		if m.t < duration {
+			break
+		}
+		m.t -= duration
+		m.segment = to
+	}
+
+	pos := m.Path[m.segment]
+	if to, ok := m.next(m.segment); ok && !m.done {
+		length := m.Path[to].Sub(pos).Len()
+		if moving := m.t - m.Wait; moving > 0 && length > 0 {
+			pos = pixel.Lerp(pos, m.Path[to], m.Ease(math.Min(1, moving*m.Speed/length)))
+		}
+	}
+	m.Rect = m.Rect.Moved(pos.Sub(old))
+	m.vel = pos.Sub(old).Scaled(1 / dt)
+}
+
+func (m *movingPlatform) AppendSurfaces(dst []Surface) []Surface {
+	dst = m.ownSurfaces(dst, m)
+	dst[len(dst)-1].Vel = dst[len(dst)-1].Vel.Add(m.vel)
+	return dst
+}
+
+// Velocity is the speed of the platform during the last update
+func (m *movingPlatform) Velocity() pixel.Vec {
+	return m.vel
+}
+
+// Configure reads "speed", "wait", "mode" (once, loop or pingpong) and "ease" (linear, inout or inoutcubic)
+func (m *movingPlatform) Configure(p Properties) error {
+	if err := m.platform.Configure(p); err != nil {
+		return err
+	}
+	var err error
+	if m.Speed, err = p.Float("speed", m.Speed); err != nil {
+		return err
+	}
+	if m.Speed <= 0 {
+		return errors.Errorf("speed must be positive, got %v", m.Speed)
+	}
+	if m.Wait, err = p.Float("wait", m.Wait); err != nil {
+		return err
+	}
+	mode, err := p.String("mode", "")
+	if err != nil {
+		return err
+	}
+	if mode != "" {
+		var ok bool
+		if m.Mode, ok = pathModes[mode]; !ok {
+			return errors.Errorf("unknown path mode %q, expected once, loop or pingpong", mode)
+		}
+	}
+	ease, err := p.String("ease", "")
+	if err != nil {
+		return err
+	}
+	if ease != "" {
+		var ok bool
+		if m.Ease, ok = easings[ease]; !ok {
+			return errors.Errorf("unknown easing %q, expected linear, inout or inoutcubic", ease)
+		}
+	}
+	return nil
+}
+
+// NewMovingPlatform creates a platform of the size of r going along the path in ping pong, starting at its first point
+func NewMovingPlatform(r pixel.Rect, path []pixel.Vec, speed float64) *movingPlatform {
+	m := &movingPlatform{
+		platform: NewPlatform(r),
+		Path:     path,
+		Mode:     PathPingPong,
+		Ease:     EaseInOut,
+		Speed:    speed,
+	}
+	if len(path) > 0 {
+		m.Rect = m.Rect.Moved(path[0].Sub(m.Rect.Center()))
+	}
+	return m
+}
@@ -0,0 +1,307 @@
+package objects
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// NavAction is how an agent goes along an edge of a navigation graph
+type NavAction int
+
+const (
+	NavWalk NavAction = iota // walk onto a surface beside the current one
+	NavDrop                  // walk off an edge and fall onto a surface below
+	NavJump                  // jump onto a surface, from its edge if there is a gap
+)
+
+func (a NavAction) String() string {
+	switch a {
+	case NavWalk:
+		return "walk"
+	case NavDrop:
+		return "drop"
+	case NavJump:
+		return "jump"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// navStep is the highest step between two surfaces walked across, and how close the feet are to a surface standing on it
+	navStep = 1
+	// navJumpCost is added to the time of every jump, so agents walk when they can
+	navJumpCost = 0.5
+)
+
+// Agent is an actor finding its way on the surfaces of a scene, the graph is built from its hitbox and movement
+type Agent interface {
+	jumpParams() jumpParams
+}
+
+// NavStep is a step of a plan: the agent goes to From on the surface it stands on,
+// then does Action to end at To on the surface of Node. From and To are where the feet are.
+type NavStep struct {
+	Action NavAction
+	From   pixel.Vec
+	To     pixel.Vec
+	Node   int
+}
+
+type navEdge struct {
+	to       int
+	action   NavAction
+	from, at float64 // x of the feet when the action starts and ends
+	cost     float64 // about the seconds it takes
+}
+
+type navNode struct {
+	rect  pixel.Rect
+	edges []navEdge
+}
+
+// navGraph links the surfaces an agent can stand on by the ways it can go from one to another.
+// It is rebuilt when the revision of the scene changes, as surfaces are added, removed, come or go.
+// Moving surfaces are taken where they are then.
+type navGraph struct {
+	scene    *scene
+	agent    Agent
+	jp       jumpParams
+	nodes    []navNode
+	revision int
+}
+
+// NewNavGraph creates the navigation graph of agent in the scene s
+func NewNavGraph(s *scene, agent Agent) *navGraph {
+	g := &navGraph{scene: s, agent: agent}
+	g.build()
+	return g
+}
+
+// Len is the number of nodes, the surfaces agents can stand on
+func (g *navGraph) Len() int {
+	g.refresh()
+	return len(g.nodes)
+}
+
+// Node returns the surface of node i
+func (g *navGraph) Node(i int) pixel.Rect {
+	return g.nodes[i].rect
+}
+
+// NodeAt returns the node an agent with its feet at feet stands on, or would land on falling straight down,
+// -1 if none. The agent stands on a surface as long as its hitbox is above it.
+func (g *navGraph) NodeAt(feet pixel.Vec) int {
+	g.refresh()
+	half := g.jp.size.X / 2
+	best := -1
+	for i, n := range g.nodes {
+		if feet.X < n.rect.Min.X-half || feet.X > n.rect.Max.X+half || n.rect.Max.Y > feet.Y+navStep {
+			continue
+		}
+		// the highest, then the one right under the feet
+		under := feet.X >= n.rect.Min.X && feet.X <= n.rect.Max.X
+		if best < 0 || n.rect.Max.Y > g.nodes[best].rect.Max.Y ||
+			n.rect.Max.Y == g.nodes[best].rect.Max.Y && under {
+			best = i
+		}
+	}
+	return best
+}
+
+func (g *navGraph) refresh() {
+	if g.revision != g.scene.Revision() {
+		g.build()
+	}
+}
+
+func (g *navGraph) build() {
+	g.revision = g.scene.Revision()
+	g.jp = g.agent.jumpParams()
+	surfaces := g.scene.Surfaces()
+	g.nodes = g.nodes[:0]
+	for i, s := range surfaces {
+		if !covered(surfaces, i) {
+			g.nodes = append(g.nodes, navNode{rect: s.Rect})
+		}
+	}
+	for i := range g.nodes {
+		g.link(i)
+	}
+}
+
+// covered reports whether the top of surfaces[i] is under another solid surface, nothing stands on it
+func covered(surfaces []Surface, i int) bool {
+	r := surfaces[i].Rect
+	for j, s := range surfaces {
+		if j != i && !s.OneWay && s.Rect.Min.Y <= r.Max.Y && s.Rect.Max.Y > r.Max.Y &&
+			s.Rect.Min.X <= r.Min.X && s.Rect.Max.X >= r.Max.X {
+			return true
+		}
+	}
+	return false
+}
+
+// link finds the edges going out of node i
+func (g *navGraph) link(i int) {
+	from := g.nodes[i].rect
+	half := g.jp.size.X / 2
+	n := &g.nodes[i]
+	n.edges = n.edges[:0]
+	linked := make(map[int]bool)
+
+	for j, to := range g.nodes {
+		t := to.rect
+		if j == i || math.Abs(t.Max.Y-from.Max.Y) > navStep ||
+			t.Min.X > from.Max.X+navStep || t.Max.X < from.Min.X-navStep {
+			continue
+		}
+		edge, dir := from.Min.X, -1.0
+		if t.Center().X > from.Center().X {
+			edge, dir = from.Max.X, +1
+		}
+		g.addEdge(i, navEdge{to: j, action: NavWalk, from: edge, at: clamp(edge+dir*half, t.Min.X, t.Max.X)})
+		linked[j] = true
+	}
+
+	// fall off each edge onto the highest surface below it
+	for _, dir := range []float64{-1, +1} {
+		edge := from.Min.X
+		if dir > 0 {
+			edge = from.Max.X
+		}
+		x := edge + dir*half
+		below := -1
+		for j, to := range g.nodes {
+			t := to.rect
+			if j == i || linked[j] || t.Max.Y >= from.Max.Y-navStep || x < t.Min.X-half || x > t.Max.X+half {
+				continue
+			}
+			if below < 0 || t.Max.Y > g.nodes[below].rect.Max.Y {
+				below = j
+			}
+		}
+		if below >= 0 {
+			t := g.nodes[below].rect
+			g.addEdge(i, navEdge{to: below, action: NavDrop, from: edge, at: clamp(x, t.Min.X, t.Max.X)})
+			linked[below] = true
+		}
+	}
+
+	for j, to := range g.nodes {
+		t := to.rect
+		if j == i || linked[j] || !g.jp.canJump(Surface{Rect: from}, Surface{Rect: t, OneWay: true}) {
+			continue
+		}
+		var start, land float64
+		switch {
+		case t.Min.X >= from.Max.X:
+			// take off with most of the hitbox past the edge
+			start, land = from.Max.X+half-navStep, t.Min.X+half
+		case t.Max.X <= from.Min.X:
+			start, land = from.Min.X-half+navStep, t.Max.X-half
+		case t.Max.Y > from.Max.Y:
+			// straight up, through the surface
+			start = clamp(t.Center().X, from.Min.X, from.Max.X)
+			land = start
+		default:
+			continue
+		}
+		g.addEdge(i, navEdge{to: j, action: NavJump, from: start, at: clamp(land, t.Min.X, t.Max.X)})
+	}
+}
+
+// addEdge adds e to node i with its cost, the time to walk from center to center plus the time in the air
+func (g *navGraph) addEdge(i int, e navEdge) {
+	from, to := g.nodes[i].rect, g.nodes[e.to].rect
+	e.cost = math.Abs(to.Center().X-from.Center().X) / g.jp.runSpeed
+	dh := to.Max.Y - from.Max.Y
+	switch e.action {
+	case NavDrop:
+		e.cost += math.Sqrt(2 * dh / g.jp.gravity)
+	case NavJump:
+		t, _ := g.jp.airTime(dh)
+		e.cost += t + navJumpCost
+	}
+	g.nodes[i].edges = append(g.nodes[i].edges, e)
+}
+
+func clamp(x, min, max float64) float64 {
+	return math.Max(min, math.Min(max, x))
+}
+
+type navItem struct {
+	node  int
+	prio  float64
+	index int
+}
+
+type navQueue []*navItem
+
+func (q navQueue) Len() int            { return len(q) }
+func (q navQueue) Less(i, j int) bool  { return q[i].prio < q[j].prio }
+func (q navQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i]; q[i].index = i; q[j].index = j }
+func (q *navQueue) Push(x interface{}) { n := x.(*navItem); n.index = len(*q); *q = append(*q, n) }
+func (q *navQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// Path plans the way of the agent from the feet at from to the surface under to with A*.
+// The plan is empty when both are on the same surface, false if there is no way.
+func (g *navGraph) Path(from, to pixel.Vec) ([]NavStep, bool) {
+	start, goal := g.NodeAt(from), g.NodeAt(to)
+	if start < 0 || goal < 0 {
+		return nil, false
+	}
+
+	// running straight there is the fastest it can be
+	guess := func(i int) float64 {
+		return math.Abs(g.nodes[goal].rect.Center().X-g.nodes[i].rect.Center().X) / g.jp.runSpeed
+	}
+	cost := make([]float64, len(g.nodes))
+	came := make([]*navEdge, len(g.nodes))
+	prev := make([]int, len(g.nodes))
+	for i := range cost {
+		cost[i] = math.Inf(1)
+	}
+	cost[start] = 0
+	queue := navQueue{{node: start, prio: guess(start)}}
+	for queue.Len() > 0 {
+		n := heap.Pop(&queue).(*navItem).node
+		if n == goal {
+			break
+		}
+		for k := range g.nodes[n].edges {
+			e := &g.nodes[n].edges[k]
+			c := cost[n] + e.cost
+			if c >= cost[e.to] {
+				continue
+			}
+			cost[e.to], came[e.to], prev[e.to] = c, e, n
+			heap.Push(&queue, &navItem{node: e.to, prio: c + guess(e.to)})
+		}
+	}
+	if math.IsInf(cost[goal], 1) {
+		return nil, false
+	}
+
+	var plan []NavStep
+	for n := goal; n != start; n = prev[n] {
+		e := came[n]
+		plan = append(plan, NavStep{
+			Action: e.action,
+			From:   pixel.V(e.from, g.nodes[prev[n]].rect.Max.Y),
+			To:     pixel.V(e.at, g.nodes[n].rect.Max.Y),
+			Node:   n,
+		})
+	}
+	for i, j := 0, len(plan)-1; i < j; i, j = i+1, j-1 {
+		plan[i], plan[j] = plan[j], plan[i]
+	}
+	return plan, true
+}
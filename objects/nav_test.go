@@ -0,0 +1,81 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// the top of each platform is where the feet are standing on it
+var (
+	navStart  = pixel.R(0, -4, 100, 0)
+	navBeside = pixel.R(100, -4, 160, 0)    // walked onto from the start
+	navBelow  = pixel.R(160, -44, 260, -40) // dropped onto from the one beside
+	navAbove  = pixel.R(-60, 20, -20, 24)   // jumped onto from the start
+	navAway   = pixel.R(-300, 200, -260, 204)
+)
+
+func newNavScene(t *testing.T, objs ...Object) (*scene, *navGraph) {
+	t.Helper()
+	s := NewScene()
+	for _, r := range []pixel.Rect{navStart, navBeside, navBelow, navAbove, navAway} {
+		s.AddObjects(NewPlatform(r))
+	}
+	s.AddObjects(objs...)
+	return s, NewNavGraph(s, newGopher(t))
+}
+
+func TestNavPath(t *testing.T) {
+	_, g := newNavScene(t)
+	from := pixel.V(50, 0)
+	for _, tc := range []struct {
+		name    string
+		to      pixel.Rect
+		actions []NavAction
+		ok      bool
+	}{
+		{"same surface", navStart, nil, true},
+		{"walk", navBeside, []NavAction{NavWalk}, true},
+		{"walk and drop", navBelow, []NavAction{NavWalk, NavDrop}, true},
+		{"jump", navAbove, []NavAction{NavJump}, true},
+		{"unreachable", navAway, nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, ok := g.Path(from, pixel.V(tc.to.Center().X, tc.to.Max.Y))
+			if ok != tc.ok {
+				t.Fatalf("found a way %v, want %v: %v", ok, tc.ok, plan)
+			}
+			var actions []NavAction
+			for _, step := range plan {
+				actions = append(actions, step.Action)
+			}
+			if !reflect.DeepEqual(actions, tc.actions) {
+				t.Errorf("plan %v, want the actions %v", actions, tc.actions)
+			}
+			if n := len(plan); n > 0 && g.Node(plan[n-1].Node) != tc.to {
+				t.Errorf("the plan ends on %v, want %v", g.Node(plan[n-1].Node), tc.to)
+			}
+		})
+	}
+}
+
+func TestNavRebuildsOnToggle(t *testing.T) {
+	// an island too far to jump to, but for a bridge switched off
+	island := pixel.R(-200, -4, -140, 0)
+	bridge := NewTogglePlatform(pixel.R(-140, -4, 0, 0), false)
+	_, g := newNavScene(t, NewPlatform(island), bridge)
+
+	from, to := pixel.V(50, 0), pixel.V(island.Center().X, island.Max.Y)
+	if plan, ok := g.Path(from, to); ok {
+		t.Fatalf("found a way to the island with the bridge switched off: %v", plan)
+	}
+	bridge.Toggle()
+	if _, ok := g.Path(from, to); !ok {
+		t.Error("no way to the island with the bridge switched on")
+	}
+	bridge.Toggle()
+	if plan, ok := g.Path(from, to); ok {
+		t.Errorf("found a way to the island with the bridge switched off again: %v", plan)
+	}
+}
@@ -10,3 +10,35 @@ type Object interface {
 	Update(float64)
 	Collide(colliders.Collider) *colliders.CollisionInfo
 }
+
+// The following interfaces are optional, the scene calls them on the objects implementing them.
+
+// Initializer is called when the object is added to a scene
+type Initializer interface {
+	Init(s *scene)
+}
+
+// Starter is called right before the first update of the object
+type Starter interface {
+	Start()
+}
+
+// SceneEnterer is called when the scene of the object becomes the current scene
+type SceneEnterer interface {
+	EnterScene(s *scene)
+}
+
+// SceneExiter is called when the scene of the object stops being the current scene
+type SceneExiter interface {
+	ExitScene(s *scene)
+}
+
+// PreUpdater is called on every object before any of them is updated
+type PreUpdater interface {
+	PreUpdate(dt float64)
+}
+
+// Destroyer is called when the object is removed from its scene
+type Destroyer interface {
+	Destroy()
+}
@@ -2,6 +2,7 @@ package objects
 
 import (
 	"image/color"
+	"math"
 
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
@@ -9,17 +10,85 @@ import (
 )
 
 type platform struct {
-	Rect  pixel.Rect
-	Color color.Color
+	Rect     pixel.Rect
+	Color    color.Color
+	Material *Material
+	// one way platforms can be jumped through from below, the others are walls the gopher can slide along
+	OneWay bool
+	Bounce float64
+	// speed of the conveyor belt on top of the platform, positive to the right
+	Conveyor float64
+
+	scene *scene
+	t     float64
+}
+
+func (p *platform) Init(s *scene) {
+	p.scene = s
+}
+
+// changed tells the scene the surfaces of the platform came or went
+func (p *platform) changed() {
+	if p.scene != nil {
+		p.scene.surfacesChanged()
+	}
 }
 
 func (p *platform) Draw(imd *imdraw.IMDraw) {
 	imd.Color = p.Color
 	imd.Push(p.Rect.Min, p.Rect.Max)
 	imd.Rectangle(0)
+	p.drawConveyor(imd, 1)
+}
+
+// drawConveyor draws the marks of the belt going along the top of the platform
+func (p *platform) drawConveyor(imd *imdraw.IMDraw, alpha float64) {
+	if p.Conveyor == 0 {
+		return
+	}
+	const spacing = 6
+	imd.Color = pixel.RGB(0, 0, 0).Mul(pixel.Alpha(0.4 * alpha))
+	shift := math.Mod(p.t*p.Conveyor, spacing)
+	if shift < 0 {
+		shift += spacing
+	}
+	for x := p.Rect.Min.X + shift; x < p.Rect.Max.X; x += spacing {
+		imd.Push(pixel.V(x, p.Rect.Max.Y-1), pixel.V(math.Min(x+2, p.Rect.Max.X), p.Rect.Max.Y))
+		imd.Rectangle(0)
+	}
 }
 
-func (p *platform) Update(dt float64) {}
+func (p *platform) Update(dt float64) {
+	p.t += dt
+}
+
+func (p *platform) AppendSurfaces(dst []Surface) []Surface {
+	return append(dst, Surface{
+		Rect:     p.Rect,
+		OneWay:   p.OneWay,
+		Owner:    p,
+		Material: p.Material,
+		Vel:      pixel.V(p.Conveyor, 0),
+		Bounce:   p.Bounce,
+	})
+}
+
+// Configure reads "bounce", the part of the speed kept bouncing off the platform, and "conveyor", the belt speed
+func (p *platform) Configure(props Properties) (err error) {
+	if p.Bounce, err = props.Float("bounce", p.Bounce); err != nil {
+		return err
+	}
+	p.Conveyor, err = props.Float("conveyor", p.Conveyor)
+	return err
+}
+
+func (p *platform) Bounds() pixel.Rect {
+	return p.Rect
+}
+
+func (p *platform) DrawLayer() (Layer, int) {
+	return LayerTerrain, 0
+}
 
 func (p *platform) Collide(col colliders.Collider) *colliders.CollisionInfo {
 	return colliders.Rect(p.Rect).Contains(col)
@@ -27,7 +96,8 @@ func (p *platform) Collide(col colliders.Collider) *colliders.CollisionInfo {
 
 func NewPlatform(r pixel.Rect) *platform {
 	return &platform{
-		Rect:  r,
-		Color: RandomNiceColor(),
+		Rect:   r,
+		Color:  RandomNiceColor(),
+		OneWay: true,
 	}
 }
@@ -0,0 +1,253 @@
+package objects
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// Stander is called every step an actor stands on a surface of the object
+type Stander interface {
+	StoodOn()
+}
+
+// Toggler objects switch between two states, like the platforms toggled by switches
+type Toggler interface {
+	Toggle()
+}
+
+// drawAlpha draws the platform with its color faded by alpha and moved by offset
+func (p *platform) drawAlpha(imd *imdraw.IMDraw, alpha float64, offset pixel.Vec) {
+	imd.Color = pixel.ToRGBA(p.Color).Scaled(alpha)
+	imd.Push(p.Rect.Min.Add(offset), p.Rect.Max.Add(offset))
+	imd.Rectangle(0)
+	p.drawConveyor(imd, alpha)
+}
+
+// ownSurfaces appends the surfaces of the platform as the surfaces of owner, the variant embedding it
+func (p *platform) ownSurfaces(dst []Surface, owner Object) []Surface {
+	dst = p.AppendSurfaces(dst)
+	dst[len(dst)-1].Owner = owner
+	return dst
+}
+
+type crumbleState int
+
+const (
+	crumbleSolid crumbleState = iota
+	crumbleShaking
+	crumbleFallen
+)
+
+// crumblingPlatform shakes once stood on and falls after Delay, to come back after Respawn if it is not 0
+type crumblingPlatform struct {
+	*platform
+	Delay   float64
+	Respawn float64
+
+	state crumbleState
+	timer float64
+	shake pixel.Vec
+}
+
+func (c *crumblingPlatform) StoodOn() {
+	if c.state == crumbleSolid {
+		c.state = crumbleShaking
+		c.timer = 0
+	}
+}
+
+func (c *crumblingPlatform) Update(dt float64) {
+	c.platform.Update(dt)
+	c.timer += dt
+	c.shake = pixel.ZV
+	switch c.state {
+	case crumbleShaking:
+		if c.timer >= c.Delay {
+			c.state = crumbleFallen
+			c.timer = 0
+			c.changed()
+			break
+		}
+		// shake harder as the fall gets closer
+		amount := c.timer / c.Delay
+		c.shake = pixel.V(rand.Float64()*2-1, rand.Float64()*2-1).Scaled(amount)
+	case crumbleFallen:
+		if c.Respawn > 0 && c.timer >= c.Respawn {
+			c.state = crumbleSolid
+			c.changed()
+		}
+	}
+}
+
+func (c *crumblingPlatform) AppendSurfaces(dst []Surface) []Surface {
+	if c.state == crumbleFallen {
+		return dst
+	}
+	return c.ownSurfaces(dst, c)
+}
+
+// Collide collides like the platform until it falls
+func (c *crumblingPlatform) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	if c.state == crumbleFallen {
+		return nil
+	}
+	return c.platform.Collide(col)
+}
+
+// Draw draws the falling platform going down and fading out, and a ghost of it until it comes back
+func (c *crumblingPlatform) Draw(imd *imdraw.IMDraw) {
+	switch c.state {
+	case crumbleFallen:
+		const fallTime = 0.4
+		if c.timer < fallTime {
+			t := c.timer / fallTime
+			c.drawAlpha(imd, 1-t, pixel.V(0, -24*t*t))
+		}
+		if c.Respawn > 0 {
+			c.drawAlpha(imd, 0.15*c.timer/c.Respawn, pixel.ZV)
+		}
+	default:
+		c.drawAlpha(imd, 1, c.shake)
+	}
+}
+
+func (c *crumblingPlatform) Configure(p Properties) (err error) {
+	if err := c.platform.Configure(p); err != nil {
+		return err
+	}
+	if c.Delay, err = p.Float("delay", c.Delay); err != nil {
+		return err
+	}
+	c.Respawn, err = p.Float("respawn", c.Respawn)
+	return err
+}
+
+func NewCrumblingPlatform(r pixel.Rect, delay, respawn float64) *crumblingPlatform {
+	return &crumblingPlatform{platform: NewPlatform(r), Delay: delay, Respawn: respawn}
+}
+
+// blinkingPlatform is solid for On seconds then gone for Off seconds, starting Offset seconds in the cycle
+type blinkingPlatform struct {
+	*platform
+	On, Off, Offset float64
+
+	clock float64
+}
+
+// blinkWarning is how long the platform flickers before disappearing
+const blinkWarning = 0.5
+
+func (b *blinkingPlatform) phase() float64 {
+	return math.Mod(b.clock+b.Offset, b.On+b.Off)
+}
+
+func (b *blinkingPlatform) Active() bool {
+	return b.phase() < b.On
+}
+
+func (b *blinkingPlatform) Update(dt float64) {
+	b.platform.Update(dt)
+	active := b.Active()
+	b.clock += dt
+	if b.Active() != active {
+		b.changed()
+	}
+}
+
+func (b *blinkingPlatform) AppendSurfaces(dst []Surface) []Surface {
+	if !b.Active() {
+		return dst
+	}
+	return b.ownSurfaces(dst, b)
+}
+
+func (b *blinkingPlatform) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	if !b.Active() {
+		return nil
+	}
+	return b.platform.Collide(col)
+}
+
+func (b *blinkingPlatform) Draw(imd *imdraw.IMDraw) {
+	alpha := 1.0
+	switch phase := b.phase(); {
+	case phase >= b.On:
+		alpha = 0.15
+	case b.On-phase < blinkWarning && int(phase*16)%2 == 0:
+		alpha = 0.5
+	}
+	b.drawAlpha(imd, alpha, pixel.ZV)
+}
+
+func (b *blinkingPlatform) Configure(p Properties) (err error) {
+	if err := b.platform.Configure(p); err != nil {
+		return err
+	}
+	if b.On, err = p.Float("on", b.On); err != nil {
+		return err
+	}
+	if b.Off, err = p.Float("off", b.Off); err != nil {
+		return err
+	}
+	if b.Offset, err = p.Float("offset", b.Offset); err != nil {
+		return err
+	}
+	if b.On <= 0 || b.Off < 0 {
+		return errors.Errorf("on must be positive and off not negative, got %v and %v", b.On, b.Off)
+	}
+	return nil
+}
+
+func NewBlinkingPlatform(r pixel.Rect, on, off, offset float64) *blinkingPlatform {
+	return &blinkingPlatform{platform: NewPlatform(r), On: on, Off: off, Offset: offset}
+}
+
+// togglePlatform is turned on and off by switches
+type togglePlatform struct {
+	*platform
+	On bool
+}
+
+func (t *togglePlatform) Toggle() {
+	t.On = !t.On
+	t.changed()
+}
+
+func (t *togglePlatform) AppendSurfaces(dst []Surface) []Surface {
+	if !t.On {
+		return dst
+	}
+	return t.ownSurfaces(dst, t)
+}
+
+func (t *togglePlatform) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	if !t.On {
+		return nil
+	}
+	return t.platform.Collide(col)
+}
+
+func (t *togglePlatform) Draw(imd *imdraw.IMDraw) {
+	alpha := 1.0
+	if !t.On {
+		alpha = 0.15
+	}
+	t.drawAlpha(imd, alpha, pixel.ZV)
+}
+
+func (t *togglePlatform) Configure(p Properties) (err error) {
+	if err := t.platform.Configure(p); err != nil {
+		return err
+	}
+	t.On, err = p.Bool("on", t.On)
+	return err
+}
+
+func NewTogglePlatform(r pixel.Rect, on bool) *togglePlatform {
+	return &togglePlatform{platform: NewPlatform(r), On: on}
+}
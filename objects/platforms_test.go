@@ -0,0 +1,42 @@
+package objects
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+func TestInactivePlatformsDontCollide(t *testing.T) {
+	r := pixel.R(0, 0, 40, 4)
+	fallen := NewCrumblingPlatform(r, 0.5, 0)
+	fallen.StoodOn()
+	fallen.Update(1)
+	off := NewBlinkingPlatform(r, 1, 1, 1.5)
+	toggled := NewTogglePlatform(r, true)
+	toggled.Toggle()
+
+	inside := colliders.Rect(pixel.R(10, 1, 20, 3))
+	for _, tc := range []struct {
+		name string
+		p    interface {
+			Object
+			Solid
+		}
+		inactive Object
+	}{
+		{"crumbling", NewCrumblingPlatform(r, 0.5, 0), fallen},
+		{"blinking", NewBlinkingPlatform(r, 1, 1, 0), off},
+		{"toggle", NewTogglePlatform(r, true), toggled},
+	} {
+		if tc.p.Collide(inside) == nil {
+			t.Errorf("%s: no collision while active", tc.name)
+		}
+		if tc.inactive.Collide(inside) != nil {
+			t.Errorf("%s: collision while inactive", tc.name)
+		}
+		if n := len(tc.inactive.(Solid).AppendSurfaces(nil)); n != 0 {
+			t.Errorf("%s: %d surfaces while inactive", tc.name, n)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package objects
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// Projectile describes the shots of an emitter
+type Projectile struct {
+	Speed float64
+	// GravityScale is the part of the gravity of the gopher pulling the shot down, 0 flies straight
+	GravityScale float64
+	Lifetime     float64 // seconds
+	// Pierce is how many actors the shot goes through, it stops on the next one. Terrain always stops it.
+	Pierce int
+	Radius float64
+	Color  pixel.RGBA
+	// Hostile shots hurt the gopher, the others hurt the enemies
+	Hostile bool
+}
+
+// shot is a projectile flying
+type shot struct {
+	Projectile
+	pos, vel pixel.Vec
+	age      float64
+	hit      []Object // actors already hit, not hit twice
+}
+
+func (sh *shot) rect() pixel.Rect {
+	return pixel.R(sh.pos.X-sh.Radius, sh.pos.Y-sh.Radius, sh.pos.X+sh.Radius, sh.pos.Y+sh.Radius)
+}
+
+// projectilePool moves, draws and collides all the shots of a scene.
+// It is a single object of the scene, shooting takes a shot done from the pool instead of adding an object.
+type projectilePool struct {
+	scene *scene
+	shots []*shot
+	free  []*shot
+}
+
+// projectiles returns the pool of the scene, added to it on the first shot
+func (s *scene) projectiles() *projectilePool {
+	if s.pool == nil {
+		s.pool = &projectilePool{scene: s}
+		s.AddObjects(s.pool)
+	}
+	return s.pool
+}
+
+// Shoot shoots a projectile from pos in the direction dir
+func (s *scene) Shoot(p Projectile, pos, dir pixel.Vec) {
+	pool := s.projectiles()
+	var sh *shot
+	if n := len(pool.free); n > 0 {
+		sh, pool.free = pool.free[n-1], pool.free[:n-1]
+	} else {
+		sh = &shot{}
+	}
+	*sh = shot{Projectile: p, pos: pos, vel: dir.Unit().Scaled(p.Speed), hit: sh.hit[:0]}
+	pool.shots = append(pool.shots, sh)
+}
+
+// done puts the shot i back in the pool
+func (pp *projectilePool) done(i int) {
+	last := len(pp.shots) - 1
+	pp.free = append(pp.free, pp.shots[i])
+	pp.shots[i] = pp.shots[last]
+	pp.shots[last] = nil
+	pp.shots = pp.shots[:last]
+}
+
+// Update moves the shots, going backwards so the shots done can be taken out while looping
+func (pp *projectilePool) Update(dt float64) {
+	s := pp.scene
+	surfaces := s.Surfaces()
+	gravity := DefaultMovement().Gravity
+	for i := len(pp.shots) - 1; i >= 0; i-- {
+		sh := pp.shots[i]
+		sh.age += dt
+		// shots far away from the view are done too, nothing would update them there
+		if sh.age >= sh.Lifetime || s.hasView && s.activityRadius > 0 && rectDist(sh.rect(), s.view) > s.activityRadius {
+			pp.done(i)
+			continue
+		}
+
+		sh.vel.Y += gravity * sh.GravityScale * dt
+		from := sh.pos
+		move := sh.vel.Scaled(dt)
+		if pp.hitsTerrain(surfaces, from, move) {
+			pp.done(i)
+			continue
+		}
+		sh.pos = from.Add(move)
+		if pp.hitsActors(sh) {
+			pp.done(i)
+		}
+	}
+}
+
+// hitsTerrain reports whether the shot moving by move from from hits a surface,
+// one way surfaces only stop it falling on them from above
+func (pp *projectilePool) hitsTerrain(surfaces []Surface, from, move pixel.Vec) bool {
+	for _, s := range surfaces {
+		if s.OneWay && (move.Y >= 0 || from.Y < s.Rect.Max.Y) {
+			continue
+		}
+		if _, ok := rayEnter(from, move, s.Rect); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hitsActors hurts the actors the shot touches, true once it went through more than Pierce of them
+func (pp *projectilePool) hitsActors(sh *shot) bool {
+	r := sh.rect()
+	done := false
+	pp.scene.each(func(e *entry) {
+		if done {
+			return
+		}
+		switch actor := e.obj.(type) {
+		case *enemy:
+			if sh.Hostile || actor.dead || !overlaps(r, actor.Rect) {
+				return
+			}
+		case *gopherAnim:
+			if !sh.Hostile || actor.Dead() || !overlaps(r, actor.Phys.Rect) {
+				return
+			}
+		default:
+			return
+		}
+		for _, o := range sh.hit {
+			if o == e.obj {
+				return
+			}
+		}
+		e.obj.(interface{ Kill() }).Kill()
+		sh.hit = append(sh.hit, e.obj)
+		done = len(sh.hit) > sh.Pierce
+	})
+	return done
+}
+
+func (pp *projectilePool) DrawLayer() (Layer, int) {
+	return LayerActors, 1
+}
+
+// Draw draws the shots in the view, fading out at the end of their life
+func (pp *projectilePool) Draw(imd *imdraw.IMDraw) {
+	for _, sh := range pp.shots {
+		if pp.scene.hasView && rectDist(sh.rect(), pp.scene.view) > 0 {
+			continue
+		}
+		imd.Color = sh.Color.Scaled(math.Min(1, (sh.Lifetime-sh.age)/0.2))
+		imd.Push(sh.pos)
+		imd.Circle(sh.Radius, 0)
+	}
+}
+
+func (pp *projectilePool) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+// emitter shoots projectiles, at most one every Interval seconds
+type emitter struct {
+	Projectile Projectile
+	Interval   float64
+	// Spread is the most the direction of a shot is turned at random, in radians
+	Spread float64
+
+	cooldown float64
+}
+
+// Update cools the emitter down, call it every step
+func (em *emitter) Update(dt float64) {
+	em.cooldown = math.Max(0, em.cooldown-dt)
+}
+
+// Fire shoots from pos towards dir in the scene s, false if the emitter is cooling down
+func (em *emitter) Fire(s *scene, pos, dir pixel.Vec) bool {
+	if em.cooldown > 0 {
+		return false
+	}
+	em.cooldown = em.Interval
+	if em.Spread > 0 {
+		dir = dir.Rotated((rand.Float64()*2 - 1) * em.Spread)
+	}
+	s.Shoot(em.Projectile, pos, dir)
+	return true
+}
+
+func NewEmitter(p Projectile, interval float64) *emitter {
+	return &emitter{Projectile: p, Interval: interval}
+}
@@ -0,0 +1,49 @@
+package objects
+
+import (
+	"fmt"
+)
+
+// Properties are the free-form parameters of an object, read from level files
+type Properties map[string]interface{}
+
+// Configurable objects read their parameters from properties
+type Configurable interface {
+	Configure(p Properties) error
+}
+
+func (p Properties) Float(name string, def float64) (float64, error) {
+	v, ok := p[name]
+	if !ok {
+		return def, nil
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	}
+	return def, fmt.Errorf("property %q: expected a number, got %T", name, v)
+}
+
+func (p Properties) String(name string, def string) (string, error) {
+	v, ok := p[name]
+	if !ok {
+		return def, nil
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return def, fmt.Errorf("property %q: expected a string, got %T", name, v)
+}
+
+func (p Properties) Bool(name string, def bool) (bool, error) {
+	v, ok := p[name]
+	if !ok {
+		return def, nil
+	}
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return def, fmt.Errorf("property %q: expected a boolean, got %T", name, v)
+}
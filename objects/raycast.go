@@ -0,0 +1,48 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Raycast returns the first point where the segment from from to to enters a surface of the scene,
+// or to and false if nothing is in the way. One way surfaces block it too.
+func (s *scene) Raycast(from, to pixel.Vec) (pixel.Vec, bool) {
+	d := to.Sub(from)
+	first := math.Inf(1)
+	for _, sf := range s.Surfaces() {
+		if t, ok := rayEnter(from, d, sf.Rect); ok && t < first {
+			first = t
+		}
+	}
+	if math.IsInf(first, 1) {
+		return to, false
+	}
+	return from.Add(d.Scaled(first)), true
+}
+
+// rayEnter returns the part of d travelled from o when entering r, between 0 and 1, false if it misses r
+func rayEnter(o, d pixel.Vec, r pixel.Rect) (float64, bool) {
+	enter, leave := 0.0, 1.0
+	for _, axis := range [2]struct{ o, d, min, max float64 }{
+		{o.X, d.X, r.Min.X, r.Max.X},
+		{o.Y, d.Y, r.Min.Y, r.Max.Y},
+	} {
+		if axis.d == 0 {
+			if axis.o < axis.min || axis.o > axis.max {
+				return 0, false
+			}
+			continue
+		}
+		t1, t2 := (axis.min-axis.o)/axis.d, (axis.max-axis.o)/axis.d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		enter, leave = math.Max(enter, t1), math.Min(leave, t2)
+		if enter > leave {
+			return 0, false
+		}
+	}
+	return enter, true
+}
@@ -0,0 +1,117 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Reachability tells which surfaces of a scene the gopher can reach from its spawn, see AnalyzeReachability
+type Reachability struct {
+	Surfaces  []Surface
+	Reachable []bool
+	// Start is the surface the gopher lands on from the spawn, -1 if it falls forever
+	Start int
+	// GoalReachable is true if the gopher can touch a goal, GoalFrom is the surface it jumps from
+	GoalReachable bool
+	GoalFrom      int
+	// CriticalPath is the shortest chain of surfaces (in jumps) from Start to GoalFrom
+	CriticalPath []int
+}
+
+// Unreachable returns the indices of the surfaces the gopher can not reach
+func (r *Reachability) Unreachable() []int {
+	var idx []int
+	for i, ok := range r.Reachable {
+		if !ok {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// canTouch reports whether an actor standing on from can touch the rectangle r while jumping
+func (jp jumpParams) canTouch(from Surface, r pixel.Rect) bool {
+	top := from.Rect.Max.Y
+	// straight up from the surface
+	if r.Max.X > from.Rect.Min.X && r.Min.X < from.Rect.Max.X &&
+		r.Max.Y >= top && r.Min.Y <= top+jp.apex()+jp.size.Y {
+		return true
+	}
+	// the last moment of a jump touching r is when the head goes below its bottom,
+	// so it is the same as landing on a surface as wide as r, the height of the actor below r
+	feet := pixel.R(r.Min.X, r.Min.Y-jp.size.Y-1, r.Max.X, r.Min.Y-jp.size.Y)
+	return jp.canJump(from, Surface{Rect: feet, OneWay: true})
+}
+
+// landing returns the surface an actor falling straight down from pos lands on, -1 if none
+func landing(surfaces []Surface, pos pixel.Vec, size pixel.Vec) int {
+	best, bestY := -1, math.Inf(-1)
+	for i, s := range surfaces {
+		if s.Rect.Max.X <= pos.X-size.X/2 || s.Rect.Min.X >= pos.X+size.X/2 {
+			continue
+		}
+		if s.Rect.Max.Y <= pos.Y-size.Y/2 && s.Rect.Max.Y > bestY {
+			best, bestY = i, s.Rect.Max.Y
+		}
+	}
+	return best
+}
+
+// AnalyzeReachability computes which surfaces of the scene the gopher can reach from the spawn,
+// with the jumps its physics allow, and whether it can reach a goal.
+// It considers single running jumps between surfaces and ignores what is in the way of the jumps.
+func AnalyzeReachability(s *scene, g *gopherAnim, spawn pixel.Vec) *Reachability {
+	jp := g.Phys.jumpParams()
+	surfaces := append([]Surface(nil), s.Surfaces()...)
+	r := &Reachability{
+		Surfaces:  surfaces,
+		Reachable: make([]bool, len(surfaces)),
+		Start:     landing(surfaces, spawn, jp.size),
+		GoalFrom:  -1,
+	}
+	if r.Start < 0 {
+		return r
+	}
+
+	var goals []pixel.Rect
+	for _, gl := range FindObjects[*goal](s) {
+		goals = append(goals, gl.Bounds())
+	}
+
+	// breadth first search, so the path to the goal has the fewest jumps
+	parent := make([]int, len(surfaces))
+	queue := []int{r.Start}
+	r.Reachable[r.Start] = true
+	parent[r.Start] = -1
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if !r.GoalReachable {
+			for _, gr := range goals {
+				if jp.canTouch(surfaces[cur], gr) {
+					r.GoalReachable = true
+					r.GoalFrom = cur
+					break
+				}
+			}
+		}
+
+		for next := range surfaces {
+			if r.Reachable[next] || !jp.canJump(surfaces[cur], surfaces[next]) {
+				continue
+			}
+			r.Reachable[next] = true
+			parent[next] = cur
+			queue = append(queue, next)
+		}
+	}
+
+	if r.GoalReachable {
+		for i := r.GoalFrom; i >= 0; i = parent[i] {
+			r.CriticalPath = append([]int{i}, r.CriticalPath...)
+		}
+	}
+	return r
+}
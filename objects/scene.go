@@ -1,28 +1,416 @@
 package objects
 
-import "github.com/faiface/pixel/imdraw"
+import (
+	"math"
+	"sort"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+)
+
+// ObjectID identifies an object inside a scene, IDs are never reused
+type ObjectID uint64
+
+type entry struct {
+	id      ObjectID
+	obj     Object
+	tags    map[string]bool
+	started bool
+	layer   Layer
+	z       int
+}
 
 type scene struct {
 	objects []Object
+	entries map[Object]*entry
+	byID    map[ObjectID]*entry
+	nextID  ObjectID
+	current bool
+	// revision counts the solid objects added and removed and the changes of their surfaces, see Revision
+	revision int
+	// the shots of the scene, added on the first shot
+	pool *projectilePool
+
+	// objects sorted by layer, z-index and id, rebuilt when dirty
+	drawOrder []*entry
+	sorted    bool
+	hidden    map[Layer]bool
+
+	// culling of the objects outside the view
+	view           pixel.Rect
+	hasView        bool
+	activityRadius float64
+	stats          CullStats
+
+	// reused by Surfaces and touchers
+	surfaces []Surface
+	touching []Toucher
+	// objects updated during this frame, reused by Update
+	awake []Object
+
+	// objects added or removed while updating are applied once the update is done
+	updating bool
+	pending  []Object
+	removed  bool
 }
 
 func (s *scene) AddObjects(o ...Object) {
-	s.objects = append(s.objects, o...)
+	for _, obj := range o {
+		if _, ok := s.entries[obj]; ok {
+			continue
+		}
+		s.nextID++
+		e := &entry{id: s.nextID, obj: obj, layer: LayerActors}
+		if l, ok := obj.(Layered); ok {
+			e.layer, e.z = l.DrawLayer()
+		}
+		s.entries[obj] = e
+		s.sorted = false
+		if _, ok := obj.(Solid); ok {
+			s.revision++
+		}
+		s.byID[e.id] = e
+		if s.updating {
+			// removed earlier in this update, it is still listed and stays where it was
+			if !s.removed || !s.listed(obj) {
+				s.pending = append(s.pending, obj)
+			}
+		} else {
+			s.objects = append(s.objects, obj)
+		}
+		if i, ok := obj.(Initializer); ok {
+			i.Init(s)
+		}
+		// keep enter and exit paired for objects joining a running scene
+		if se, ok := obj.(SceneEnterer); ok && s.current {
+			se.EnterScene(s)
+		}
+	}
+}
+
+func (s *scene) RemoveObjects(o ...Object) {
+	for _, obj := range o {
+		e, ok := s.entries[obj]
+		if !ok {
+			continue
+		}
+		delete(s.entries, obj)
+		delete(s.byID, e.id)
+		s.removed = true
+		if _, ok := obj.(Solid); ok {
+			s.revision++
+		}
+		s.sorted = false
+		if se, ok := obj.(SceneExiter); ok && s.current {
+			se.ExitScene(s)
+		}
+		if d, ok := obj.(Destroyer); ok {
+			d.Destroy()
+		}
+	}
+	if !s.updating {
+		s.flush()
+	}
+}
+
+// listed reports whether the object is in the objects or the pending ones, removed or not
+func (s *scene) listed(obj Object) bool {
+	for _, objs := range [][]Object{s.objects, s.pending} {
+		for _, o := range objs {
+			if o == obj {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flush applies the pending additions and removals to the objects slice
+func (s *scene) flush() {
+	if !s.removed && len(s.pending) == 0 {
+		return
+	}
+	objs := s.objects[:0]
+	for _, obj := range s.objects {
+		if _, ok := s.entries[obj]; ok {
+			objs = append(objs, obj)
+		}
+	}
+	for _, obj := range s.pending {
+		if _, ok := s.entries[obj]; ok {
+			objs = append(objs, obj)
+		}
+	}
+	for i := len(objs); i < len(s.objects); i++ {
+		s.objects[i] = nil // let the removed objects be collected
+	}
+	s.objects = objs
+	s.pending = s.pending[:0]
+	s.removed = false
+}
+
+// Revision changes every time a solid object is added to or removed from the scene, or its surfaces come or go.
+// What is built from its surfaces is rebuilt when it changes.
+func (s *scene) Revision() int {
+	return s.revision
+}
+
+// surfacesChanged is called by the solid objects whose surfaces come or go without leaving the scene
+func (s *scene) surfacesChanged() {
+	s.revision++
+}
+
+// Contains reports whether the object is in the scene (or pending to be added)
+func (s *scene) Contains(o Object) bool {
+	_, ok := s.entries[o]
+	return ok
+}
+
+// ID returns the id of the object, 0 if it is not in the scene
+func (s *scene) ID(o Object) ObjectID {
+	if e, ok := s.entries[o]; ok {
+		return e.id
+	}
+	return 0
+}
+
+// Object returns the object with the given id, nil if there is none
+func (s *scene) Object(id ObjectID) Object {
+	if e, ok := s.byID[id]; ok {
+		return e.obj
+	}
+	return nil
+}
+
+func (s *scene) Tag(o Object, tags ...string) {
+	e, ok := s.entries[o]
+	if !ok {
+		return
+	}
+	if e.tags == nil {
+		e.tags = make(map[string]bool, len(tags))
+	}
+	for _, t := range tags {
+		e.tags[t] = true
+	}
+}
+
+func (s *scene) Untag(o Object, tags ...string) {
+	e, ok := s.entries[o]
+	if !ok {
+		return
+	}
+	for _, t := range tags {
+		delete(e.tags, t)
+	}
+}
+
+func (s *scene) HasTag(o Object, tag string) bool {
+	e, ok := s.entries[o]
+	return ok && e.tags[tag]
+}
+
+// Tagged returns the objects with the given tag, in insertion order
+func (s *scene) Tagged(tag string) []Object {
+	var tagged []Object
+	s.each(func(e *entry) {
+		if e.tags[tag] {
+			tagged = append(tagged, e.obj)
+		}
+	})
+	return tagged
+}
+
+// each calls f for every object in the scene, including the pending ones
+func (s *scene) each(f func(e *entry)) {
+	for _, obj := range s.objects {
+		if e, ok := s.entries[obj]; ok {
+			f(e)
+		}
+	}
+	for _, obj := range s.pending {
+		if e, ok := s.entries[obj]; ok {
+			f(e)
+		}
+	}
+}
+
+// FindObjects returns the objects of the scene of type T, in insertion order
+func FindObjects[T Object](s *scene) []T {
+	var found []T
+	s.each(func(e *entry) {
+		if t, ok := e.obj.(T); ok {
+			found = append(found, t)
+		}
+	})
+	return found
 }
 
 func (s *scene) Update(dt float64) {
+	// a long frame would move the objects through each other, and jump less high than planned
+	dt = math.Min(dt, MaxStep)
+	if s == Game.currentScene {
+		Game.tick(dt)
+	}
+	s.updating = true
+	s.stats.Updated, s.stats.Asleep = 0, 0
+	s.awake = s.awake[:0]
 	for _, obj := range s.objects {
+		if !s.active(obj) {
+			s.stats.Asleep++
+			continue
+		}
+		s.awake = append(s.awake, obj)
+	}
+
+	// objects carrying others move first, so the objects they carry move along in their update
+	for _, obj := range s.awake {
+		if pu, ok := obj.(PreUpdater); ok && s.start(obj) {
+			pu.PreUpdate(dt)
+		}
+	}
+	for _, obj := range s.awake {
+		// skip the objects removed during this update
+		if !s.start(obj) {
+			continue
+		}
+		s.stats.Updated++
 		obj.Update(dt)
 	}
+	s.updating = false
+	s.flush()
+}
+
+// start starts the object before its first update, false if it was removed from the scene
+func (s *scene) start(obj Object) bool {
+	e, ok := s.entries[obj]
+	if !ok {
+		return false
+	}
+	if !e.started {
+		e.started = true
+		if st, ok := obj.(Starter); ok {
+			st.Start()
+		}
+	}
+	return true
+}
+
+// enter is called by the game when the scene becomes the current one
+func (s *scene) enter() {
+	s.current = true
+	s.each(func(e *entry) {
+		if se, ok := e.obj.(SceneEnterer); ok {
+			se.EnterScene(s)
+		}
+	})
+}
+
+// exit is called by the game when the scene stops being the current one
+func (s *scene) exit() {
+	s.current = false
+	s.each(func(e *entry) {
+		if se, ok := e.obj.(SceneExiter); ok {
+			se.ExitScene(s)
+		}
+	})
+}
+
+// SetLayer moves the object to the given layer and z-index
+func (s *scene) SetLayer(o Object, l Layer, z int) {
+	e, ok := s.entries[o]
+	if !ok {
+		return
+	}
+	e.layer, e.z = l, z
+	s.sorted = false
+}
+
+// Layer returns the layer and z-index of the object
+func (s *scene) Layer(o Object) (Layer, int) {
+	if e, ok := s.entries[o]; ok {
+		return e.layer, e.z
+	}
+	return 0, 0
+}
+
+func (s *scene) SetLayerVisible(l Layer, visible bool) {
+	if visible {
+		delete(s.hidden, l)
+	} else {
+		s.hidden[l] = true
+	}
+}
+
+func (s *scene) LayerVisible(l Layer) bool {
+	return !s.hidden[l]
 }
+
+// ToggleLayer shows the layer if it is hidden, hides it otherwise
+func (s *scene) ToggleLayer(l Layer) {
+	s.SetLayerVisible(l, s.hidden[l])
+}
+
+func (s *scene) sortDrawOrder() {
+	s.drawOrder = s.drawOrder[:0]
+	s.each(func(e *entry) {
+		s.drawOrder = append(s.drawOrder, e)
+	})
+	sort.Slice(s.drawOrder, func(i, j int) bool {
+		a, b := s.drawOrder[i], s.drawOrder[j]
+		if a.layer != b.layer {
+			return a.layer < b.layer
+		}
+		if a.z != b.z {
+			return a.z < b.z
+		}
+		return a.id < b.id
+	})
+	s.sorted = true
+}
+
+// Draw draws the objects with IMDraw, leaving the batches of the batch drawers out, see DrawTo
 func (s *scene) Draw(imd *imdraw.IMDraw) {
-	for _, obj := range s.objects {
-		obj.Draw(imd)
+	s.draw(imd, nil)
+}
+
+// DrawTo draws the scene onto t in draw order. The IMDraw shapes are flushed to t before each batch drawer,
+// so its batch goes above the shapes of the objects before it and below the ones after it.
+func (s *scene) DrawTo(t pixel.Target, imd *imdraw.IMDraw) {
+	s.draw(imd, t)
+	imd.Draw(t)
+	imd.Clear()
+}
+
+// draw draws the objects with imd, and the batches onto t unless it is nil
+func (s *scene) draw(imd *imdraw.IMDraw, t pixel.Target) {
+	if !s.sorted {
+		s.sortDrawOrder()
+	}
+	s.stats.Drawn, s.stats.Culled = 0, 0
+	for _, e := range s.drawOrder {
+		if s.hidden[e.layer] {
+			continue
+		}
+		if !s.visible(e.obj) {
+			s.stats.Culled++
+			continue
+		}
+		s.stats.Drawn++
+		e.obj.Draw(imd)
+		if b, ok := e.obj.(BatchDrawer); ok && t != nil {
+			imd.Draw(t)
+			imd.Clear()
+			b.DrawBatch(t)
+		}
 	}
 }
 
 func NewScene() *scene {
 	return &scene{
 		objects: make([]Object, 0),
+		entries: make(map[Object]*entry),
+		byID:    make(map[ObjectID]*entry),
+		hidden:  make(map[Layer]bool),
 	}
 }
@@ -0,0 +1,94 @@
+package objects
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel/imdraw"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// testObject counts its updates and runs onUpdate in each of them
+type testObject struct {
+	updates  int
+	onUpdate func()
+}
+
+func (o *testObject) Draw(*imdraw.IMDraw) {}
+
+func (o *testObject) Update(dt float64) {
+	o.updates++
+	if o.onUpdate != nil {
+		o.onUpdate()
+	}
+}
+
+func (o *testObject) Collide(colliders.Collider) *colliders.CollisionInfo {
+	return nil
+}
+
+func TestSceneDeferredChanges(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		was    bool // in the scene before the update
+		change func(s *scene, o *testObject)
+		in     bool
+	}{
+		{"add", false, func(s *scene, o *testObject) { s.AddObjects(o) }, true},
+		{"remove", true, func(s *scene, o *testObject) { s.RemoveObjects(o) }, false},
+		{"remove then add", true, func(s *scene, o *testObject) { s.RemoveObjects(o); s.AddObjects(o) }, true},
+		{"add then remove", false, func(s *scene, o *testObject) { s.AddObjects(o); s.RemoveObjects(o) }, false},
+		{"add, remove, add", false, func(s *scene, o *testObject) {
+			s.AddObjects(o)
+			s.RemoveObjects(o)
+			s.AddObjects(o)
+		}, true},
+		{"remove, add, remove, add", true, func(s *scene, o *testObject) {
+			s.RemoveObjects(o)
+			s.AddObjects(o)
+			s.RemoveObjects(o)
+			s.AddObjects(o)
+		}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewScene()
+			o := &testObject{}
+			if tc.was {
+				s.AddObjects(o)
+			}
+			changer := &testObject{}
+			changer.onUpdate = func() {
+				if changer.updates == 1 {
+					tc.change(s, o)
+				}
+			}
+			s.AddObjects(changer)
+
+			s.Update(1.0 / 60)
+			before := o.updates
+			s.Update(1.0 / 60)
+
+			n := 0
+			for _, obj := range s.objects {
+				if obj == o {
+					n++
+				}
+			}
+			want, updates := 0, 0
+			if tc.in {
+				want, updates = 1, 1
+			}
+			if n != want {
+				t.Errorf("the object is %d times in the scene, want %d", n, want)
+			}
+			if s.Contains(o) != tc.in {
+				t.Errorf("Contains() = %v, want %v", s.Contains(o), tc.in)
+			}
+			if got := o.updates - before; got != updates {
+				t.Errorf("updated %d times in the next update, want %d", got, updates)
+			}
+			if got := len(FindObjects[*testObject](s)); got != want+1 {
+				t.Errorf("FindObjects found %d objects, want %d", got, want+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package objects
+
+import "github.com/faiface/pixel"
+
+// Surface is a rectangle actors collide against
+type Surface struct {
+	Rect pixel.Rect
+	// one way surfaces only stop actors falling on them from above
+	OneWay bool
+	// the object the surface belongs to
+	Owner Object
+	// what the ground is made of, nil is normal ground
+	Material *Material
+	// how fast actors standing on the surface are carried, the speed of moving platforms and conveyor belts
+	Vel pixel.Vec
+	// part of the speed actors keep when they hit the surface, they bounce off it when above 0
+	Bounce float64
+}
+
+// minBounceSpeed is the slowest speed actors bounce off surfaces at, slower they stop against them
+const minBounceSpeed = 32
+
+// Solid objects have surfaces actors collide against
+type Solid interface {
+	// AppendSurfaces appends the current surfaces of the object to dst
+	AppendSurfaces(dst []Surface) []Surface
+}
+
+// overlapEpsilon keeps actors resting on a surface from overlapping it because of rounding errors
+const overlapEpsilon = 1e-6
+
+// overlaps reports whether two rectangles overlap by more than touching
+func overlaps(a, b pixel.Rect) bool {
+	return a.Max.X-b.Min.X > overlapEpsilon && b.Max.X-a.Min.X > overlapEpsilon &&
+		a.Max.Y-b.Min.Y > overlapEpsilon && b.Max.Y-a.Min.Y > overlapEpsilon
+}
+
+// Surfaces returns the surfaces of every solid object of the scene,
+// the slice is reused by the next call.
+func (s *scene) Surfaces() []Surface {
+	s.surfaces = s.surfaces[:0]
+	for _, obj := range s.objects {
+		if _, ok := s.entries[obj]; !ok {
+			continue
+		}
+		if sol, ok := obj.(Solid); ok {
+			s.surfaces = sol.AppendSurfaces(s.surfaces)
+		}
+	}
+	return s.surfaces
+}
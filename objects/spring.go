@@ -0,0 +1,113 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// springSquash is how long the spring stays squashed after a launch
+const springSquash = 0.2
+
+// spring launches the gopher touching it in its direction
+type spring struct {
+	Pos      pixel.Vec // middle of the base
+	Dir      pixel.Vec // unit vector, up by default
+	Strength float64
+	// time the controls don't steer the gopher after a launch, so sideways springs throw it far
+	Lock float64
+
+	squash float64 // time left of the squash animation
+}
+
+func (s *spring) Touch(gp *gopherPhys) {
+	// already flying away
+	if gp.Vel.Dot(s.Dir) > 0 {
+		return
+	}
+	lock := 0.0
+	if math.Abs(s.Dir.X) > 0.1 {
+		lock = s.Lock
+	}
+	gp.Launch(s.Dir.Scaled(s.Strength), lock)
+	if !gp.simulated {
+		s.squash = springSquash
+	}
+}
+
+func (s *spring) Update(dt float64) {
+	s.squash = math.Max(0, s.squash-dt)
+}
+
+func (s *spring) Bounds() pixel.Rect {
+	c := s.Pos.Add(s.Dir.Scaled(4))
+	return pixel.R(c.X-5, c.Y-5, c.X+5, c.Y+5)
+}
+
+func (s *spring) DrawLayer() (Layer, int) {
+	return LayerActors, 0
+}
+
+// Draw draws a base, a coil and a pad along the direction of the spring, squashed after a launch
+func (s *spring) Draw(imd *imdraw.IMDraw) {
+	height := 8.0
+	if s.squash > 0 {
+		height = 3 + 5*(1-s.squash/springSquash)
+	}
+	m := pixel.IM.Rotated(pixel.ZV, s.Dir.Angle()-math.Pi/2).Moved(s.Pos)
+
+	imd.Color = pixel.RGB(0.5, 0.5, 0.5)
+	imd.Push(m.Project(pixel.V(-5, 0)), m.Project(pixel.V(5, 1)))
+	imd.Rectangle(0)
+
+	imd.Color = pixel.RGB(0.8, 0.8, 0.8)
+	const turns = 3
+	for i := 0; i <= 2*turns; i++ {
+		x := 3.0
+		if i%2 == 1 {
+			x = -3
+		}
+		imd.Push(m.Project(pixel.V(x, 1+(height-2)*float64(i)/(2*turns))))
+	}
+	imd.Line(1)
+
+	imd.Color = pixel.RGB(0.9, 0.2, 0.2)
+	imd.Push(m.Project(pixel.V(-5, height-1)), m.Project(pixel.V(5, height)))
+	imd.Rectangle(0)
+}
+
+func (s *spring) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return colliders.Rect(s.Bounds()).Contains(col)
+}
+
+// Configure reads "strength", "lock" and "angle", the direction in degrees, 90 is up
+func (s *spring) Configure(p Properties) (err error) {
+	if s.Strength, err = p.Float("strength", s.Strength); err != nil {
+		return err
+	}
+	if s.Lock, err = p.Float("lock", s.Lock); err != nil {
+		return err
+	}
+	angle, err := p.Float("angle", s.Dir.Angle()*180/math.Pi)
+	if err != nil {
+		return err
+	}
+	s.Dir = pixel.Unit(angle * math.Pi / 180)
+	if s.Strength <= 0 {
+		return errors.Errorf("strength must be positive, got %v", s.Strength)
+	}
+	return nil
+}
+
+// NewSpring creates a spring standing on pos launching up at strength
+func NewSpring(pos pixel.Vec, strength float64) *spring {
+	return &spring{
+		Pos:      pos,
+		Dir:      pixel.V(0, 1),
+		Strength: strength,
+		Lock:     0.3,
+	}
+}
@@ -0,0 +1,59 @@
+package objects
+
+// LevelStats tells how the gopher is doing on the current level
+type LevelStats struct {
+	Level     string  `json:"level"`
+	Score     int     `json:"score"`
+	Collected int     `json:"collected"`
+	Total     int     `json:"total"` // collectibles in the level when it started
+	Deaths    int     `json:"deaths"`
+	Time      float64 `json:"time"` // seconds
+	Completed bool    `json:"completed"`
+}
+
+// StartLevel resets the stats for the level of the current scene, counting its collectibles.
+// The collectibles picked up since the last start come back, and their value leaves the score, to play the level again.
+func (g *game) StartLevel(name string) {
+	for _, c := range g.picked {
+		g.Score -= c.Value
+		c.scene.AddObjects(c)
+	}
+	g.picked = g.picked[:0]
+	g.level = LevelStats{Level: name}
+	if g.currentScene != nil {
+		g.level.Total = len(FindObjects[*collectible](g.currentScene))
+	}
+}
+
+// Stats returns the stats of the current level
+func (g *game) Stats() LevelStats {
+	return g.level
+}
+
+// CompleteLevel stops the clock of the level and calls OnComplete, once
+func (g *game) CompleteLevel() {
+	if g.level.Completed {
+		return
+	}
+	g.level.Completed = true
+	if g.OnComplete != nil {
+		g.OnComplete(g.level)
+	}
+}
+
+func (g *game) collect(c *collectible) {
+	g.picked = append(g.picked, c)
+	g.Score += c.Value
+	g.level.Score += c.Value
+	g.level.Collected++
+}
+
+func (g *game) died() {
+	g.level.Deaths++
+}
+
+func (g *game) tick(dt float64) {
+	if !g.level.Completed {
+		g.level.Time += dt
+	}
+}
@@ -0,0 +1,43 @@
+package objects
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestRestartLevel(t *testing.T) {
+	gp := newTestGopher(t, pixel.R(-100, -2, 100, 0), 0)
+	coin, gem := NewCoin(pixel.V(20, 4)), NewGem(pixel.V(40, 4))
+	Game.currentScene.AddObjects(coin, gem)
+	score := Game.Score
+	Game.StartLevel("test")
+	t.Cleanup(func() { Game.StartLevel("") })
+
+	coin.Touch(gp)
+	Game.died()
+	Game.tick(1)
+	Game.CompleteLevel()
+	if st := Game.Stats(); st.Collected != 1 || !st.Completed {
+		t.Fatalf("the level did not go on: %+v", st)
+	}
+
+	Game.StartLevel("test")
+	if st := Game.Stats(); st != (LevelStats{Level: "test", Total: 2}) {
+		t.Errorf("the stats after restarting are %+v, want a level starting over", st)
+	}
+	if !Game.currentScene.Contains(coin) || !Game.currentScene.Contains(gem) {
+		t.Error("the coin picked up did not come back after restarting")
+	}
+	if Game.Score != score {
+		t.Errorf("the score is %d after restarting, want %d from before the level", Game.Score, score)
+	}
+
+	completed := 0
+	Game.OnComplete = func(LevelStats) { completed++ }
+	t.Cleanup(func() { Game.OnComplete = nil })
+	Game.CompleteLevel()
+	if completed != 1 {
+		t.Errorf("completing the restarted level called OnComplete %d times, want 1", completed)
+	}
+}
@@ -0,0 +1,93 @@
+package objects
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// switchButton toggles the objects tagged with Target when the gopher touches it
+type switchButton struct {
+	Rect   pixel.Rect
+	Target string
+	// Once switches can only be pressed one time
+	Once bool
+
+	scene   *scene
+	touched bool
+	pressed bool
+}
+
+func (sw *switchButton) Init(s *scene) {
+	sw.scene = s
+}
+
+func (sw *switchButton) Update(dt float64) {
+	touching := false
+	for _, g := range FindObjects[*gopherAnim](sw.scene) {
+		if overlaps(g.Bounds(), sw.Rect) {
+			touching = true
+			break
+		}
+	}
+	// toggle when the gopher comes in, not while it stays on the switch
+	if touching && !sw.touched && !(sw.Once && sw.pressed) {
+		sw.Press()
+	}
+	sw.touched = touching
+}
+
+// Press toggles the target objects
+func (sw *switchButton) Press() {
+	sw.pressed = !sw.pressed
+	for _, obj := range sw.scene.Tagged(sw.Target) {
+		if t, ok := obj.(Toggler); ok {
+			t.Toggle()
+		}
+	}
+}
+
+func (sw *switchButton) Bounds() pixel.Rect {
+	return sw.Rect
+}
+
+func (sw *switchButton) DrawLayer() (Layer, int) {
+	return LayerActors, 0
+}
+
+// Draw draws a button, pushed in once pressed
+func (sw *switchButton) Draw(imd *imdraw.IMDraw) {
+	base := pixel.R(sw.Rect.Min.X, sw.Rect.Min.Y, sw.Rect.Max.X, sw.Rect.Min.Y+2)
+	imd.Color = pixel.RGB(0.5, 0.5, 0.5)
+	imd.Push(base.Min, base.Max)
+	imd.Rectangle(0)
+
+	top := sw.Rect.Max.Y
+	imd.Color = pixel.RGB(0.9, 0.2, 0.2)
+	if sw.pressed {
+		top = base.Max.Y + 1
+		imd.Color = pixel.RGB(0.2, 0.9, 0.2)
+	}
+	imd.Push(pixel.V(sw.Rect.Min.X+2, base.Max.Y), pixel.V(sw.Rect.Max.X-2, top))
+	imd.Rectangle(0)
+}
+
+func (sw *switchButton) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	return colliders.Rect(sw.Rect).Contains(col)
+}
+
+func (sw *switchButton) Configure(p Properties) (err error) {
+	if sw.Target, err = p.String("target", sw.Target); err != nil {
+		return err
+	}
+	sw.Once, err = p.Bool("once", sw.Once)
+	return err
+}
+
+// NewSwitch creates a switch standing on pos, toggling the objects tagged with target
+func NewSwitch(pos pixel.Vec, target string) *switchButton {
+	return &switchButton{
+		Rect:   pixel.R(pos.X-5, pos.Y, pos.X+5, pos.Y+5),
+		Target: target,
+	}
+}
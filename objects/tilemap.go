@@ -0,0 +1,295 @@
+package objects
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/unknownTravelers/3D-jump-infinite/colliders"
+)
+
+// the colliders of a tilemap are merged inside chunks of tilemapChunk*tilemapChunk tiles,
+// so changing a tile only merges its chunk again
+const tilemapChunk = 16
+
+// BatchDrawer objects draw from their own picture instead of the picture of the scene IMDraw
+type BatchDrawer interface {
+	DrawBatch(t pixel.Target)
+}
+
+type tilemap struct {
+	Origin   pixel.Vec // bottom left corner of the map
+	TileSize pixel.Vec
+	// color of the terrain when there is no tileset
+	Color color.Color
+	// material of every tile
+	Material *Material
+	// decoration maps are only drawn, behind the terrain, nothing collides against them
+	Decoration bool
+
+	cols, rows int
+	tiles      []int // row by row from the bottom, 0 is empty
+
+	tileset pixel.Picture
+	frames  []pixel.Rect // frames[id-1] is the frame of the tile id
+	batch   *pixel.Batch
+	sprite  *pixel.Sprite
+	redraw  bool
+
+	// merged rectangles of each chunk, in world space
+	chunkCols, chunkRows int
+	chunks               [][]pixel.Rect
+	dirty                []bool
+	merged               colliders.Collider
+
+	scene *scene
+}
+
+// mergeCells covers the true cells of a w*h grid with as few rectangles as possible,
+// growing each rectangle along x then y (greedy meshing). Rectangles are [minX, minY, maxX, maxY) in cells.
+func mergeCells(cells []bool, w, h int) [][4]int {
+	used := make([]bool, len(cells))
+	free := func(x, y int) bool { return cells[y*w+x] && !used[y*w+x] }
+
+	var rects [][4]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !free(x, y) {
+				continue
+			}
+			x1 := x + 1
+			for x1 < w && free(x1, y) {
+				x1++
+			}
+			y1 := y + 1
+		grow:
+			for y1 < h {
+				for i := x; i < x1; i++ {
+					if !free(i, y1) {
+						break grow
+					}
+				}
+				y1++
+			}
+			for j := y; j < y1; j++ {
+				for i := x; i < x1; i++ {
+					used[j*w+i] = true
+				}
+			}
+			rects = append(rects, [4]int{x, y, x1, y1})
+		}
+	}
+	return rects
+}
+
+func (t *tilemap) Size() (cols, rows int) {
+	return t.cols, t.rows
+}
+
+// Tile returns the tile id at the column x and row y (from the bottom), 0 outside of the map
+func (t *tilemap) Tile(x, y int) int {
+	if x < 0 || y < 0 || x >= t.cols || y >= t.rows {
+		return 0
+	}
+	return t.tiles[y*t.cols+x]
+}
+
+func (t *tilemap) Init(s *scene) {
+	t.scene = s
+}
+
+func (t *tilemap) SetTile(x, y, id int) {
+	if x < 0 || y < 0 || x >= t.cols || y >= t.rows {
+		return
+	}
+	i := y*t.cols + x
+	if t.tiles[i] == id {
+		return
+	}
+	// only the chunk has to be merged again when a tile becomes solid or empty
+	if (t.tiles[i] == 0) != (id == 0) {
+		t.dirty[(y/tilemapChunk)*t.chunkCols+x/tilemapChunk] = true
+		if t.scene != nil && !t.Decoration {
+			t.scene.surfacesChanged()
+		}
+	}
+	t.tiles[i] = id
+	t.redraw = true
+}
+
+// TileAt returns the column and row of the tile at pos
+func (t *tilemap) TileAt(pos pixel.Vec) (x, y int, ok bool) {
+	p := pos.Sub(t.Origin)
+	if p.X < 0 || p.Y < 0 {
+		return 0, 0, false
+	}
+	x, y = int(p.X/t.TileSize.X), int(p.Y/t.TileSize.Y)
+	return x, y, x < t.cols && y < t.rows
+}
+
+// TileRect returns the world rectangle of the tile at the column x and row y
+func (t *tilemap) TileRect(x, y int) pixel.Rect {
+	min := t.Origin.Add(pixel.V(float64(x)*t.TileSize.X, float64(y)*t.TileSize.Y))
+	return pixel.Rect{Min: min, Max: min.Add(t.TileSize)}
+}
+
+// mergeChunk merges the solid tiles of a chunk into rectangles
+func (t *tilemap) mergeChunk(cx, cy int) {
+	x0, y0 := cx*tilemapChunk, cy*tilemapChunk
+	w, h := min(tilemapChunk, t.cols-x0), min(tilemapChunk, t.rows-y0)
+	cells := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cells[y*w+x] = t.tiles[(y0+y)*t.cols+x0+x] != 0
+		}
+	}
+
+	i := cy*t.chunkCols + cx
+	t.chunks[i] = t.chunks[i][:0]
+	for _, r := range mergeCells(cells, w, h) {
+		t.chunks[i] = append(t.chunks[i], pixel.Rect{
+			Min: t.TileRect(x0+r[0], y0+r[1]).Min,
+			Max: t.TileRect(x0+r[2]-1, y0+r[3]-1).Max,
+		})
+	}
+}
+
+// regenerate merges the dirty chunks again and rebuilds the colliders
+func (t *tilemap) regenerate() {
+	changed := false
+	for i, d := range t.dirty {
+		if d {
+			t.mergeChunk(i%t.chunkCols, i/t.chunkCols)
+			t.dirty[i] = false
+			changed = true
+		}
+	}
+	if !changed && t.merged != nil {
+		return
+	}
+	var cols []colliders.Collider
+	for _, rects := range t.chunks {
+		for _, r := range rects {
+			cols = append(cols, colliders.Rect(r))
+		}
+	}
+	t.merged = colliders.M(cols...)
+}
+
+// Rects returns the merged collision rectangles of the map
+func (t *tilemap) Rects() []pixel.Rect {
+	t.regenerate()
+	var rects []pixel.Rect
+	for _, c := range t.chunks {
+		rects = append(rects, c...)
+	}
+	return rects
+}
+
+func (t *tilemap) AppendSurfaces(dst []Surface) []Surface {
+	if t.Decoration {
+		return dst
+	}
+	t.regenerate()
+	for _, rects := range t.chunks {
+		for _, r := range rects {
+			dst = append(dst, Surface{Rect: r, Owner: t, Material: t.Material})
+		}
+	}
+	return dst
+}
+
+func (t *tilemap) Update(dt float64) {
+	t.regenerate()
+}
+
+func (t *tilemap) Collide(col colliders.Collider) *colliders.CollisionInfo {
+	if t.Decoration {
+		return nil
+	}
+	t.regenerate()
+	return t.merged.Contains(col)
+}
+
+func (t *tilemap) Bounds() pixel.Rect {
+	return pixel.Rect{
+		Min: t.Origin,
+		Max: t.Origin.Add(pixel.V(float64(t.cols)*t.TileSize.X, float64(t.rows)*t.TileSize.Y)),
+	}
+}
+
+func (t *tilemap) DrawLayer() (Layer, int) {
+	if t.Decoration {
+		return LayerBackground, 0
+	}
+	return LayerTerrain, 0
+}
+
+// Draw draws the merged rectangles when there is no tileset, the tiles are drawn by DrawBatch
+func (t *tilemap) Draw(imd *imdraw.IMDraw) {
+	if t.tileset != nil {
+		return
+	}
+	imd.Color = t.Color
+	for _, r := range t.Rects() {
+		imd.Push(r.Min, r.Max)
+		imd.Rectangle(0)
+	}
+}
+
+func (t *tilemap) DrawBatch(target pixel.Target) {
+	if t.tileset == nil {
+		return
+	}
+	if t.redraw {
+		t.batch.Clear()
+		for y := 0; y < t.rows; y++ {
+			for x := 0; x < t.cols; x++ {
+				id := t.tiles[y*t.cols+x]
+				if id <= 0 || id > len(t.frames) {
+					continue
+				}
+				r := t.TileRect(x, y)
+				t.sprite.Set(t.tileset, t.frames[id-1])
+				t.sprite.Draw(t.batch, pixel.IM.
+					ScaledXY(pixel.ZV, pixel.V(r.W()/t.sprite.Frame().W(), r.H()/t.sprite.Frame().H())).
+					Moved(r.Center()),
+				)
+			}
+		}
+		t.redraw = false
+	}
+	t.batch.Draw(target)
+}
+
+// NewTilemap creates an empty map of cols*rows tiles of the given size, its bottom left corner at origin.
+// The tileset is cut in frames of the tile size, the tile id 1 is its top left frame, then left to right and
+// top to bottom like Tiled. Without tileset the solid tiles are drawn as rectangles of the map Color.
+func NewTilemap(origin, tileSize pixel.Vec, cols, rows int, tileset pixel.Picture) *tilemap {
+	t := &tilemap{
+		Origin:    origin,
+		TileSize:  tileSize,
+		Color:     RandomNiceColor(),
+		cols:      cols,
+		rows:      rows,
+		tiles:     make([]int, cols*rows),
+		tileset:   tileset,
+		chunkCols: (cols + tilemapChunk - 1) / tilemapChunk,
+		chunkRows: (rows + tilemapChunk - 1) / tilemapChunk,
+	}
+	t.chunks = make([][]pixel.Rect, t.chunkCols*t.chunkRows)
+	t.dirty = make([]bool, len(t.chunks))
+
+	if tileset != nil {
+		b := tileset.Bounds()
+		for y := b.Max.Y - tileSize.Y; y >= b.Min.Y; y -= tileSize.Y {
+			for x := b.Min.X; x+tileSize.X <= b.Max.X; x += tileSize.X {
+				t.frames = append(t.frames, pixel.R(x, y, x+tileSize.X, y+tileSize.Y))
+			}
+		}
+		t.batch = pixel.NewBatch(&pixel.TrianglesData{}, tileset)
+		t.sprite = pixel.NewSprite(nil, pixel.Rect{})
+		t.redraw = true
+	}
+	return t
+}
@@ -0,0 +1,41 @@
+package objects
+
+import "github.com/faiface/pixel"
+
+// Toucher objects are told every step the gopher overlaps their bounds, they can change its physics
+type Toucher interface {
+	Bounded
+	Touch(gp *gopherPhys)
+}
+
+// touchers returns the touchers of the scene, the slice is reused by the next call
+func (s *scene) touchers() []Toucher {
+	s.touching = s.touching[:0]
+	for _, obj := range s.objects {
+		if _, ok := s.entries[obj]; !ok {
+			continue
+		}
+		if t, ok := obj.(Toucher); ok {
+			s.touching = append(s.touching, t)
+		}
+	}
+	return s.touching
+}
+
+// touch tells the touchers the gopher overlaps
+func (gp *gopherPhys) touch() {
+	for _, t := range Game.currentScene.touchers() {
+		if overlaps(gp.Rect, t.Bounds()) {
+			t.Touch(gp)
+		}
+	}
+}
+
+// Launch throws the gopher at vel, the controls don't steer it for lock seconds
+func (gp *gopherPhys) Launch(vel pixel.Vec, lock float64) {
+	gp.Vel = vel
+	gp.ground = false
+	gp.coyote = 0
+	gp.jumping, gp.hang = false, false
+	gp.lockout = lock
+}